@@ -7,8 +7,12 @@ import (
 	"os"
 	"time"
 
+	"shipsgame/internal/archive"
+	"shipsgame/internal/bot"
 	"shipsgame/internal/config"
 	httpapi "shipsgame/internal/http"
+	"shipsgame/internal/matchmaking"
+	"shipsgame/internal/store/postgres"
 	redisstore "shipsgame/internal/store/redis"
 	"shipsgame/internal/ws"
 )
@@ -30,24 +34,108 @@ func main() {
 		logger.Printf("redis ping failed: %v", err)
 	}
 
+	var archiver *archive.Archiver
+	var users httpapi.UserStore
+	var historyHandler *httpapi.HistoryHandler
+	var ratings matchmaking.RatingSource
+	pgClient, err := postgres.NewClient(context.Background(), postgres.Config{DSN: cfg.PostgresDSN})
+	if err != nil {
+		logger.Printf("postgres init failed, finished games will not be archived: %v", err)
+	} else {
+		defer pgClient.Close()
+		if err := pgClient.Pool.Ping(context.Background()); err != nil {
+			logger.Printf("postgres ping failed: %v", err)
+		}
+		pgStore := postgres.NewStore(pgClient.Pool)
+		pgStore.Ratings = postgres.RatingConfig{K: cfg.RatingK, MinGames: cfg.LeaderboardMinGames}
+		users = pgStore
+		ratings = pgStore
+		historyHandler = &httpapi.HistoryHandler{Store: pgStore, Logger: logger}
+		archiver = &archive.Archiver{
+			Redis:  redisClient,
+			Store:  pgStore,
+			TTL:    cfg.ArchiveTTL,
+			Logger: logger,
+		}
+	}
+
+	aiPlayer := bot.NewPlayer(redisClient, time.Now().UnixNano())
+
 	hub := ws.NewHub()
-	go hub.Run()
+	switch cfg.Broadcaster {
+	case config.BroadcasterMemory:
+		hub.Broadcaster = ws.NewMemoryBroadcaster()
+	case config.BroadcasterStreams:
+		hub.Broadcaster = redisstore.NewStream(redisClient, cfg.InstanceID)
+	default:
+		hub.Broadcaster = redisstore.NewPubSub(redisClient)
+	}
+	logger.Printf("ws broadcaster=%s instance=%s", cfg.Broadcaster, cfg.InstanceID)
 	wsServer := &ws.Server{
 		Hub:       hub,
 		Store:     redisClient,
+		Bot:       aiPlayer,
 		JWTSecret: cfg.JWTSecret,
 		Logger:    logger,
+
+		ChatFilter: ws.NewBlocklistFilter(cfg.ChatBlocklist),
+	}
+	hub.Presence = wsServer
+	go hub.Run()
+	go wsServer.RunTurnSweeper(context.Background(), cfg.TurnSweepInterval)
+	if archiver != nil {
+		worker := &archive.Worker{
+			Archiver: archiver,
+			Redis:    redisClient,
+			Instance: cfg.InstanceID,
+			Logger:   logger,
+		}
+		go worker.Run(context.Background())
 	}
 
+	matchmaker := &matchmaking.Matchmaker{
+		Redis:     redisClient,
+		Ratings:   ratings,
+		JWTSecret: cfg.JWTSecret,
+		Logger:    logger,
+		Notify: func(userID string, match matchmaking.Match) {
+			wsServer.NotifyUser(userID, "match_found", match)
+		},
+		Expired: func(userID string) {
+			wsServer.NotifyUser(userID, "matchmaking_expired", struct{}{})
+		},
+	}
+	go matchmaker.Run(context.Background(), cfg.MatchmakingInterval)
+
 	gamesHandler := &httpapi.GamesHandler{
 		Store:     redisClient,
+		Bot:       aiPlayer,
+		JWTSecret: cfg.JWTSecret,
+		Logger:    logger,
+		LobbyTTL:  cfg.LobbyTTL,
+
+		OnFinished: wsServer.GameFinished,
+	}
+
+	authHandler := &httpapi.AuthHandler{
+		Users:     users,
 		JWTSecret: cfg.JWTSecret,
+		TokenTTL:  cfg.UserTokenTTL,
 		Logger:    logger,
 	}
 
+	matchmakingHandler := &httpapi.MatchmakingHandler{
+		Matchmaker: matchmaker,
+		JWTSecret:  cfg.JWTSecret,
+		Logger:     logger,
+	}
+
 	mux := httpapi.NewRouter(httpapi.RouterConfig{
-		WsHandler:    wsServer.Handler(),
-		GamesHandler: gamesHandler,
+		WsHandler:          wsServer.Handler(),
+		AuthHandler:        authHandler,
+		GamesHandler:       gamesHandler,
+		HistoryHandler:     historyHandler,
+		MatchmakingHandler: matchmakingHandler,
 		CORS: httpapi.CORSConfig{
 			AllowedOrigins: cfg.CORSOrigins,
 		},
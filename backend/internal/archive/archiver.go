@@ -0,0 +1,141 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"shipsgame/internal/store/postgres"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const (
+	defaultAttempts = 5
+	defaultBackoff  = 500 * time.Millisecond
+)
+
+var ErrGameNotFinished = errors.New("game not finished")
+
+type Saver interface {
+	SaveGame(ctx context.Context, summary postgres.GameSummary, events []postgres.GameEvent) error
+}
+
+// Archiver copies finished games from Redis into Postgres and then lets the
+// Redis keys expire after TTL. SaveGame is idempotent, so archiving the same
+// game twice, or from two instances at once, stores it once.
+type Archiver struct {
+	Redis    *redisstore.Client
+	Store    Saver
+	TTL      time.Duration
+	Attempts int
+	Backoff  time.Duration
+	Logger   *log.Logger
+}
+
+func (a *Archiver) Archive(ctx context.Context, gameID string) error {
+	meta, err := a.Redis.GetMeta(ctx, gameID)
+	if err != nil {
+		return err
+	}
+	if meta.Status != "finished" {
+		return ErrGameNotFinished
+	}
+
+	events, err := a.Redis.GetEvents(ctx, gameID, 0)
+	if err != nil {
+		return err
+	}
+
+	summary, rows, err := Summarize(meta, events)
+	if err != nil {
+		return err
+	}
+	if err := a.save(ctx, summary, rows); err != nil {
+		return err
+	}
+
+	if a.TTL > 0 {
+		if err := a.Redis.ExpireGame(ctx, gameID, a.TTL); err != nil {
+			return err
+		}
+	}
+	if a.Logger != nil {
+		a.Logger.Printf("game archived game_id=%s events=%d reason=%s", gameID, len(rows), summary.Reason)
+	}
+	return nil
+}
+
+func (a *Archiver) save(ctx context.Context, summary postgres.GameSummary, rows []postgres.GameEvent) error {
+	attempts := a.Attempts
+	if attempts <= 0 {
+		attempts = defaultAttempts
+	}
+	backoff := a.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = a.Store.SaveGame(ctx, summary, rows); err == nil {
+			return nil
+		}
+		if a.Logger != nil {
+			a.Logger.Printf("archive attempt failed game_id=%s attempt=%d err=%v", summary.GameID, attempt, err)
+		}
+		if attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// Summarize turns a finished game and its event log into the rows stored in
+// Postgres. StartedAt is the first event and FinishedAt the finished event.
+// Players are the users bound to the seats; the AI seat has none.
+func Summarize(meta redisstore.GameMeta, events []redisstore.GameEvent) (postgres.GameSummary, []postgres.GameEvent, error) {
+	summary := postgres.GameSummary{
+		GameID:    meta.ID,
+		Player1ID: meta.P1UserID,
+		Player2ID: meta.P2UserID,
+		Status:    meta.Status,
+		Reason:    meta.FinishReason,
+	}
+	switch meta.Winner {
+	case "p1":
+		summary.WinnerID, summary.LoserID = meta.P1UserID, meta.P2UserID
+	case "p2":
+		summary.WinnerID, summary.LoserID = meta.P2UserID, meta.P1UserID
+	}
+
+	rows := make([]postgres.GameEvent, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return postgres.GameSummary{}, nil, err
+		}
+		at := time.UnixMilli(event.At).UTC()
+		rows = append(rows, postgres.GameEvent{
+			Seq:       int(event.Seq),
+			EventType: event.Type,
+			Payload:   payload,
+			CreatedAt: at,
+		})
+
+		if summary.StartedAt == nil {
+			summary.StartedAt = &at
+		}
+		if event.Type == redisstore.EventFinished {
+			summary.FinishedAt = &at
+		}
+	}
+	return summary, rows, nil
+}
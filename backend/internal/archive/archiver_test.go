@@ -0,0 +1,126 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/store/postgres"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+type fakeSaver struct {
+	failures int
+	calls    int
+	summary  postgres.GameSummary
+	events   []postgres.GameEvent
+}
+
+func (f *fakeSaver) SaveGame(_ context.Context, summary postgres.GameSummary, events []postgres.GameEvent) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("connection refused")
+	}
+	f.summary = summary
+	f.events = events
+	return nil
+}
+
+func newTestArchiver(t *testing.T, saver *fakeSaver) (*Archiver, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	t.Cleanup(func() { _ = store.Close() })
+
+	return &Archiver{
+		Redis:    store,
+		Store:    saver,
+		TTL:      time.Hour,
+		Attempts: 3,
+		Backoff:  time.Millisecond,
+	}, server
+}
+
+func TestArchiveSavesFinishedGame(t *testing.T) {
+	saver := &fakeSaver{failures: 2}
+	archiver, server := newTestArchiver(t, saver)
+
+	ctx := context.Background()
+	meta, err := archiver.Redis.CreateGame(ctx, redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if err := archiver.Archive(ctx, meta.ID); err != ErrGameNotFinished {
+		t.Fatalf("expected game not finished, got %v", err)
+	}
+	if _, err := archiver.Redis.Resign(ctx, meta.ID, "p1"); err != nil {
+		t.Fatalf("resign: %v", err)
+	}
+
+	if err := archiver.Archive(ctx, meta.ID); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if saver.calls != 3 {
+		t.Fatalf("expected success on third attempt, got %d calls", saver.calls)
+	}
+	if saver.summary.GameID != meta.ID || saver.summary.Reason != redisstore.FinishAbandoned {
+		t.Fatalf("unexpected summary: %+v", saver.summary)
+	}
+	if saver.summary.StartedAt == nil || saver.summary.FinishedAt == nil {
+		t.Fatalf("expected start and finish times, got %+v", saver.summary)
+	}
+	if len(saver.events) != 2 || saver.events[1].EventType != redisstore.EventFinished {
+		t.Fatalf("unexpected events: %+v", saver.events)
+	}
+
+	if ttl := server.TTL("game:" + meta.ID + ":meta"); ttl != time.Hour {
+		t.Fatalf("expected meta ttl of 1h, got %s", ttl)
+	}
+}
+
+func TestArchiveGivesUpAfterAttempts(t *testing.T) {
+	saver := &fakeSaver{failures: 10}
+	archiver, server := newTestArchiver(t, saver)
+
+	ctx := context.Background()
+	meta, err := archiver.Redis.CreateGame(ctx, redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if _, err := archiver.Redis.Resign(ctx, meta.ID, "p1"); err != nil {
+		t.Fatalf("resign: %v", err)
+	}
+
+	if err := archiver.Archive(ctx, meta.ID); err == nil {
+		t.Fatalf("expected archive to fail")
+	}
+	if saver.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", saver.calls)
+	}
+	if ttl := server.TTL("game:" + meta.ID + ":meta"); ttl != 0 {
+		t.Fatalf("expected no ttl before archiving succeeded, got %s", ttl)
+	}
+}
+
+func TestSummarizeMapsSeatsToUsers(t *testing.T) {
+	meta := redisstore.GameMeta{
+		ID:           "game",
+		Status:       "finished",
+		Winner:       "p2",
+		FinishReason: redisstore.FinishAllSunk,
+		P1UserID:     "user-1",
+		P2UserID:     "user-2",
+	}
+
+	summary, _, err := Summarize(meta, nil)
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if summary.Player1ID != "user-1" || summary.Player2ID != "user-2" {
+		t.Fatalf("unexpected players: %+v", summary)
+	}
+	if summary.WinnerID != "user-2" || summary.LoserID != "user-1" {
+		t.Fatalf("unexpected winner and loser: %+v", summary)
+	}
+}
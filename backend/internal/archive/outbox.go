@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const (
+	defaultMaxFailures  = 3
+	defaultPollInterval = time.Second
+	defaultStaleAfter   = 2 * time.Minute
+)
+
+// Worker drains the archive outbox. A game is claimed onto this instance's
+// processing list and only removed once it is in Postgres; a game that keeps
+// failing ends up on the dead-letter list instead of blocking the outbox.
+// Several workers, on one or many instances, can drain the same outbox.
+type Worker struct {
+	Archiver     *Archiver
+	Redis        *redisstore.Client
+	Instance     string
+	MaxFailures  int
+	PollInterval time.Duration
+	// StaleAfter is how long an instance may go without a heartbeat before
+	// other workers requeue the games it had claimed.
+	StaleAfter time.Duration
+	Logger     *log.Logger
+}
+
+// Run processes the outbox until ctx is done. Games left claimed by a previous
+// run of the same instance are requeued first. While running, the worker
+// heartbeats and requeues the games of instances that stopped heartbeating,
+// such as a replaced container that came back under a new name.
+func (w *Worker) Run(ctx context.Context) {
+	poll := w.PollInterval
+	if poll <= 0 {
+		poll = defaultPollInterval
+	}
+	staleAfter := w.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleAfter
+	}
+
+	if n, err := w.Redis.RequeueArchives(ctx, w.Instance); err != nil {
+		w.logf("archive requeue failed instance=%s err=%v", w.Instance, err)
+	} else if n > 0 {
+		w.logf("archive requeued instance=%s games=%d", w.Instance, n)
+	}
+
+	var lastBeat time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastBeat) >= staleAfter/4 {
+			lastBeat = time.Now()
+			w.heartbeat(ctx, lastBeat, staleAfter)
+		}
+
+		gameID, err := w.Redis.ClaimArchive(ctx, w.Instance, poll)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logf("archive claim failed err=%v", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(poll):
+				}
+			}
+			continue
+		}
+		if gameID != "" {
+			w.process(ctx, gameID)
+		}
+	}
+}
+
+func (w *Worker) heartbeat(ctx context.Context, now time.Time, staleAfter time.Duration) {
+	if err := w.Redis.HeartbeatArchiver(ctx, w.Instance, now); err != nil {
+		w.logf("archive heartbeat failed instance=%s err=%v", w.Instance, err)
+		return
+	}
+	if n, err := w.Redis.ReapArchives(ctx, now.Add(-staleAfter)); err != nil {
+		w.logf("archive reap failed err=%v", err)
+	} else if n > 0 {
+		w.logf("archive reaped stale claims games=%d", n)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, gameID string) {
+	err := w.Archiver.Archive(ctx, gameID)
+	if err == nil {
+		if err := w.Redis.AckArchive(ctx, w.Instance, gameID); err != nil {
+			w.logf("archive ack failed game_id=%s err=%v", gameID, err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Left on the processing list; the next start requeues it.
+		return
+	}
+
+	maxFailures := w.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = defaultMaxFailures
+	}
+	if errors.Is(err, redisstore.ErrGameNotFound) || errors.Is(err, ErrGameNotFinished) {
+		maxFailures = 1
+	}
+
+	dead, failErr := w.Redis.FailArchive(ctx, w.Instance, gameID, maxFailures)
+	if failErr != nil {
+		w.logf("archive fail bookkeeping failed game_id=%s err=%v", gameID, failErr)
+		return
+	}
+	if dead {
+		w.logf("archive dead-lettered game_id=%s err=%v", gameID, err)
+	} else {
+		w.logf("archive failed, requeued game_id=%s err=%v", gameID, err)
+	}
+}
+
+func (w *Worker) logf(format string, args ...any) {
+	if w.Logger != nil {
+		w.Logger.Printf(format, args...)
+	}
+}
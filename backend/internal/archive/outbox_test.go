@@ -0,0 +1,106 @@
+package archive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func runWorker(t *testing.T, worker *Worker, until func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !until() {
+		if time.Now().After(deadline) {
+			cancel()
+			<-done
+			t.Fatalf("worker did not finish in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func finishedGame(t *testing.T, client *redisstore.Client) string {
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if _, err := client.Resign(ctx, meta.ID, "p1"); err != nil {
+		t.Fatalf("resign: %v", err)
+	}
+	return meta.ID
+}
+
+func TestWorkerArchivesQueuedGame(t *testing.T) {
+	saver := &fakeSaver{failures: 1}
+	archiver, server := newTestArchiver(t, saver)
+	gameID := finishedGame(t, archiver.Redis)
+
+	worker := &Worker{Archiver: archiver, Redis: archiver.Redis, Instance: "a", PollInterval: 10 * time.Millisecond}
+	runWorker(t, worker, func() bool {
+		return server.TTL("game:"+gameID+":meta") == time.Hour
+	})
+
+	if saver.summary.GameID != gameID {
+		t.Fatalf("expected %s saved, got %+v", gameID, saver.summary)
+	}
+	if server.Exists("games:archive_processing:a") {
+		t.Fatalf("expected archived game acked")
+	}
+}
+
+func TestWorkerDeadLettersFailingGame(t *testing.T) {
+	saver := &fakeSaver{failures: 100}
+	archiver, server := newTestArchiver(t, saver)
+	archiver.Attempts = 1
+	gameID := finishedGame(t, archiver.Redis)
+
+	worker := &Worker{Archiver: archiver, Redis: archiver.Redis, Instance: "a", MaxFailures: 2, PollInterval: 10 * time.Millisecond}
+	runWorker(t, worker, func() bool {
+		dead, _ := archiver.Redis.DeadArchives(context.Background())
+		return len(dead) == 1 && dead[0] == gameID
+	})
+
+	if saver.calls != 2 {
+		t.Fatalf("expected 2 attempts before dead-lettering, got %d", saver.calls)
+	}
+	if ttl := server.TTL("game:" + gameID + ":meta"); ttl != 0 {
+		t.Fatalf("expected dead game kept in redis, got ttl %s", ttl)
+	}
+}
+
+func TestWorkerReapsGamesOfStaleInstance(t *testing.T) {
+	saver := &fakeSaver{}
+	archiver, server := newTestArchiver(t, saver)
+	gameID := finishedGame(t, archiver.Redis)
+
+	ctx := context.Background()
+	if id, err := archiver.Redis.ClaimArchive(ctx, "replaced", time.Millisecond); err != nil || id != gameID {
+		t.Fatalf("expected claim by the replaced instance, got %q %v", id, err)
+	}
+	if err := archiver.Redis.HeartbeatArchiver(ctx, "replaced", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("heartbeat: %v", err)
+	}
+
+	worker := &Worker{Archiver: archiver, Redis: archiver.Redis, Instance: "a", PollInterval: 10 * time.Millisecond, StaleAfter: time.Minute}
+	runWorker(t, worker, func() bool {
+		return server.TTL("game:"+gameID+":meta") == time.Hour
+	})
+
+	if saver.summary.GameID != gameID {
+		t.Fatalf("expected %s saved, got %+v", gameID, saver.summary)
+	}
+	if server.Exists("games:archive_processing:replaced") {
+		t.Fatalf("expected stale claim reaped")
+	}
+}
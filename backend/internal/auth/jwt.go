@@ -7,23 +7,45 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Claims admit a connection to one game, either in a seat (Player) or, with
+// RoleSpectator, as a watcher with no seat.
 type Claims struct {
 	GameID string `json:"game_id"`
-	Player string `json:"player"`
+	Player string `json:"player,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Tokens without a role are player tokens.
+const (
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+)
+
+func (c Claims) IsSpectator() bool {
+	return c.Role == RoleSpectator
+}
+
+// UserClaims identify a user across games. They are issued once at sign-up and
+// exchanged for a game token when the user creates or joins a game.
+type UserClaims struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Scope       string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// userScope marks user tokens so a game token, which also names its user, is
+// never accepted in place of one.
+const userScope = "user"
+
 func ParseToken(token string, secret string) (Claims, error) {
 	if token == "" {
 		return Claims{}, errors.New("missing token")
 	}
 
-	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if t.Method != jwt.SigningMethodHS256 {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+	parsed, err := jwt.ParseWithClaims(token, &Claims{}, keyFunc(secret))
 	if err != nil {
 		return Claims{}, err
 	}
@@ -32,9 +54,36 @@ func ParseToken(token string, secret string) (Claims, error) {
 	if !ok || !parsed.Valid {
 		return Claims{}, errors.New("invalid token")
 	}
-	if strings.TrimSpace(claims.GameID) == "" || strings.TrimSpace(claims.Player) == "" {
+	if strings.TrimSpace(claims.GameID) == "" {
 		return Claims{}, errors.New("missing claims")
 	}
+	switch claims.Role {
+	case "", RolePlayer:
+		if strings.TrimSpace(claims.Player) == "" {
+			return Claims{}, errors.New("missing claims")
+		}
+	case RoleSpectator:
+		if claims.Player != "" {
+			return Claims{}, errors.New("spectator token names a player")
+		}
+	default:
+		return Claims{}, errors.New("unknown role")
+	}
+	return *claims, nil
+}
+
+func ParseUserToken(token string, secret string) (UserClaims, error) {
+	if token == "" {
+		return UserClaims{}, errors.New("missing token")
+	}
+
+	claims := &UserClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, keyFunc(secret)); err != nil {
+		return UserClaims{}, err
+	}
+	if claims.Scope != userScope || strings.TrimSpace(claims.UserID) == "" {
+		return UserClaims{}, errors.New("missing claims")
+	}
 	return *claims, nil
 }
 
@@ -42,3 +91,18 @@ func SignToken(secret string, claims Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
+
+func SignUserToken(secret string, claims UserClaims) (string, error) {
+	claims.Scope = userScope
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+func keyFunc(secret string) jwt.Keyfunc {
+	return func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secret), nil
+	}
+}
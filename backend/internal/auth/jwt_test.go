@@ -56,3 +56,63 @@ func TestParseTokenInvalid(t *testing.T) {
 		t.Fatalf("expected error for missing claims")
 	}
 }
+
+func TestParseUserToken(t *testing.T) {
+	signed, err := SignUserToken("secret", UserClaims{
+		UserID:      "user-1",
+		DisplayName: "Ada",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	parsed, err := ParseUserToken(signed, "secret")
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if parsed.UserID != "user-1" || parsed.DisplayName != "Ada" {
+		t.Fatalf("unexpected claims: %+v", parsed)
+	}
+	if _, err := ParseToken(signed, "secret"); err == nil {
+		t.Fatalf("expected user token to be rejected as a game token")
+	}
+
+	gameToken, err := SignToken("secret", Claims{GameID: "game-1", Player: "p1", UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := ParseUserToken(gameToken, "secret"); err == nil {
+		t.Fatalf("expected game token to be rejected as a user token")
+	}
+}
+
+func TestParseSpectatorToken(t *testing.T) {
+	signed, err := SignToken("secret", Claims{GameID: "game-1", UserID: "user-1", Role: RoleSpectator})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	parsed, err := ParseToken(signed, "secret")
+	if err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if !parsed.IsSpectator() || parsed.Player != "" {
+		t.Fatalf("unexpected claims: %+v", parsed)
+	}
+
+	for _, claims := range []Claims{
+		{GameID: "game-1", Player: "p1", Role: RoleSpectator},
+		{GameID: "game-1", Role: "referee"},
+		{GameID: "game-1", Role: RolePlayer},
+	} {
+		signed, err := SignToken("secret", claims)
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		if _, err := ParseToken(signed, "secret"); err == nil {
+			t.Fatalf("expected %+v to be rejected", claims)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+// Seat is the player slot the bot occupies in games against the AI.
+const Seat = "p2"
+
+type Move struct {
+	Coord  game.Coord
+	Result redisstore.ShotResult
+}
+
+type Turn struct {
+	Player string
+	Salvo  bool
+	Moves  []Move
+}
+
+// Player plays the AI seat through the same store calls as human clients, so
+// rules and atomicity are enforced by the Redis scripts.
+type Player struct {
+	Store *redisstore.Client
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewPlayer(store *redisstore.Client, seed int64) *Player {
+	return &Player{
+		Store: store,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (p *Player) PlaceFleet(ctx context.Context, gameID string) error {
+	meta, err := p.Store.GetMeta(ctx, gameID)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	ships, err := game.RandomFleet(meta.Rules, p.rng, game.FleetConstraints{})
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return p.Store.PlaceShips(ctx, gameID, Seat, redisstore.ShipsPlacement(ships))
+}
+
+// TakeTurn fires the bot's shots when it is the bot's move in an active AI
+// game. It returns an empty Turn when there is nothing to do.
+func (p *Player) TakeTurn(ctx context.Context, gameID string) (Turn, error) {
+	meta, err := p.Store.GetMeta(ctx, gameID)
+	if err != nil {
+		return Turn{}, err
+	}
+	if meta.Opponent != redisstore.OpponentAI || meta.Status != "active" || meta.Turn != Seat {
+		return Turn{}, nil
+	}
+
+	state, err := p.Store.GetState(ctx, gameID, Seat)
+	if err != nil {
+		return Turn{}, err
+	}
+
+	difficulty, err := ParseDifficulty(meta.Difficulty)
+	if err != nil {
+		difficulty = Medium
+	}
+
+	turn := Turn{Player: Seat, Salvo: meta.Rules.IsSalvo()}
+	if !turn.Salvo {
+		p.mu.Lock()
+		coord, err := ChooseShot(difficulty, meta.Rules, state.Shots, p.rng)
+		p.mu.Unlock()
+		if err != nil {
+			return Turn{}, err
+		}
+
+		result, err := p.Store.Fire(ctx, gameID, Seat, coord)
+		if err != nil {
+			return Turn{}, err
+		}
+		turn.Moves = []Move{{Coord: coord, Result: result}}
+		return turn, nil
+	}
+
+	sunk := 0
+	for _, outcome := range state.IncomingShots {
+		if strings.HasPrefix(outcome, "sunk:") {
+			sunk++
+		}
+	}
+
+	p.mu.Lock()
+	coords, err := ChooseShots(difficulty, meta.Rules, state.Shots, meta.Rules.SalvoSize(len(state.Ships)-sunk), p.rng)
+	p.mu.Unlock()
+	if err != nil {
+		return Turn{}, err
+	}
+
+	results, err := p.Store.FireSalvo(ctx, gameID, Seat, coords)
+	if err != nil {
+		return Turn{}, err
+	}
+	for i, result := range results {
+		turn.Moves = append(turn.Moves, Move{Coord: coords[i], Result: result})
+	}
+	return turn, nil
+}
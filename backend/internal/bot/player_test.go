@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func TestPlayerPlacesAndFires(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	defer func() {
+		_ = store.Close()
+	}()
+
+	ctx := context.Background()
+	meta, err := store.CreateGame(ctx, redisstore.GameOptions{Opponent: redisstore.OpponentAI, Difficulty: string(Hard)})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if meta.JoinCode != "" {
+		t.Fatalf("expected no join code for ai game")
+	}
+
+	player := NewPlayer(store, 42)
+	if err := player.PlaceFleet(ctx, meta.ID); err != nil {
+		t.Fatalf("place fleet: %v", err)
+	}
+
+	turn, err := player.TakeTurn(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("take turn before start: %v", err)
+	}
+	if len(turn.Moves) != 0 {
+		t.Fatalf("expected no moves before the game is active")
+	}
+
+	if err := store.PlaceShips(ctx, meta.ID, "p1", redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+	if _, err := store.Fire(ctx, meta.ID, "p1", game.Coord{Row: 9, Col: 9}); err != nil {
+		t.Fatalf("fire p1: %v", err)
+	}
+
+	turn, err = player.TakeTurn(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("take turn: %v", err)
+	}
+	if len(turn.Moves) != 1 || turn.Player != Seat {
+		t.Fatalf("expected one bot move, got %+v", turn)
+	}
+
+	stored, err := store.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if stored.Turn != "p1" {
+		t.Fatalf("expected turn back to p1, got %s", stored.Turn)
+	}
+}
@@ -0,0 +1,283 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"shipsgame/internal/game"
+)
+
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+var (
+	ErrUnknownDifficulty = errors.New("unknown difficulty")
+	ErrNoTargets         = errors.New("no cells left to shoot")
+)
+
+func ParseDifficulty(value string) (Difficulty, error) {
+	switch Difficulty(value) {
+	case "":
+		return Medium, nil
+	case Easy, Medium, Hard:
+		return Difficulty(value), nil
+	default:
+		return "", ErrUnknownDifficulty
+	}
+}
+
+type cellState int
+
+const (
+	cellUnknown cellState = iota
+	cellMiss
+	cellHit
+	cellSunk
+)
+
+type boardView struct {
+	rules game.Ruleset
+	cells map[game.Coord]cellState
+	sunk  map[game.ShipType]bool
+}
+
+// newBoardView builds the bot's picture of the opponent board from its own
+// shots hash ("row,col" -> miss|hit|sunk:{ship}). Anything else, such as
+// shots already chosen for the current salvo, counts as a miss.
+func newBoardView(rules game.Ruleset, shots map[string]string) boardView {
+	view := boardView{
+		rules: rules,
+		cells: make(map[game.Coord]cellState, len(shots)),
+		sunk:  make(map[game.ShipType]bool),
+	}
+	for key, outcome := range shots {
+		var coord game.Coord
+		if _, err := fmt.Sscanf(key, "%d,%d", &coord.Row, &coord.Col); err != nil {
+			continue
+		}
+		switch {
+		case outcome == "hit":
+			view.cells[coord] = cellHit
+		case strings.HasPrefix(outcome, "sunk:"):
+			view.cells[coord] = cellSunk
+			view.sunk[game.ShipType(strings.TrimPrefix(outcome, "sunk:"))] = true
+		default:
+			view.cells[coord] = cellMiss
+		}
+	}
+	return view
+}
+
+func (v boardView) unknown() []game.Coord {
+	cells := []game.Coord{}
+	for row := 0; row < v.rules.Height; row++ {
+		for col := 0; col < v.rules.Width; col++ {
+			coord := game.Coord{Row: row, Col: col}
+			if v.cells[coord] == cellUnknown {
+				cells = append(cells, coord)
+			}
+		}
+	}
+	return cells
+}
+
+func (v boardView) isUnknown(coord game.Coord) bool {
+	return v.rules.InBounds(coord) && v.cells[coord] == cellUnknown
+}
+
+func (v boardView) remainingSizes() []int {
+	sizes := []int{}
+	for _, id := range v.rules.ShipIDs() {
+		if v.sunk[id] {
+			continue
+		}
+		size, _ := v.rules.ShipSize(id)
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// unresolvedHits reports whether some hits belong to ships that are still
+// afloat, i.e. there are more hit cells than cells of sunk ships.
+func (v boardView) unresolvedHits() bool {
+	hits := 0
+	for _, state := range v.cells {
+		if state == cellHit || state == cellSunk {
+			hits++
+		}
+	}
+	sunkCells := 0
+	for id := range v.sunk {
+		size, _ := v.rules.ShipSize(id)
+		sunkCells += size
+	}
+	return hits > sunkCells
+}
+
+func ChooseShot(difficulty Difficulty, rules game.Ruleset, shots map[string]string, rng *rand.Rand) (game.Coord, error) {
+	view := newBoardView(rules, shots)
+	unknown := view.unknown()
+	if len(unknown) == 0 {
+		return game.Coord{}, ErrNoTargets
+	}
+
+	switch difficulty {
+	case Hard:
+		return densityShot(view, unknown, rng), nil
+	case Medium:
+		if targets := targetCells(view); len(targets) > 0 {
+			return targets[rng.Intn(len(targets))], nil
+		}
+		return huntShot(unknown, rng), nil
+	default:
+		return unknown[rng.Intn(len(unknown))], nil
+	}
+}
+
+func ChooseShots(difficulty Difficulty, rules game.Ruleset, shots map[string]string, count int, rng *rand.Rand) ([]game.Coord, error) {
+	pending := make(map[string]string, len(shots)+count)
+	for key, outcome := range shots {
+		pending[key] = outcome
+	}
+
+	coords := make([]game.Coord, 0, count)
+	for i := 0; i < count; i++ {
+		coord, err := ChooseShot(difficulty, rules, pending, rng)
+		if err != nil {
+			if len(coords) > 0 {
+				break
+			}
+			return nil, err
+		}
+		coords = append(coords, coord)
+		pending[fmt.Sprintf("%d,%d", coord.Row, coord.Col)] = "pending"
+	}
+	return coords, nil
+}
+
+var directions = []game.Coord{{Row: 0, Col: 1}, {Row: 1, Col: 0}, {Row: 0, Col: -1}, {Row: -1, Col: 0}}
+
+// targetCells returns the cells worth shooting after a hit: both ends of a
+// line of hits when one exists, otherwise the neighbours of any hit.
+func targetCells(view boardView) []game.Coord {
+	if !view.unresolvedHits() {
+		return nil
+	}
+
+	hits := []game.Coord{}
+	for row := 0; row < view.rules.Height; row++ {
+		for col := 0; col < view.rules.Width; col++ {
+			coord := game.Coord{Row: row, Col: col}
+			if view.cells[coord] == cellHit {
+				hits = append(hits, coord)
+			}
+		}
+	}
+
+	lineEnds := []game.Coord{}
+	neighbours := []game.Coord{}
+	for _, hit := range hits {
+		for _, dir := range directions {
+			next := game.Coord{Row: hit.Row + dir.Row, Col: hit.Col + dir.Col}
+			if view.isUnknown(next) {
+				neighbours = append(neighbours, next)
+				back := game.Coord{Row: hit.Row - dir.Row, Col: hit.Col - dir.Col}
+				if view.cells[back] == cellHit {
+					lineEnds = append(lineEnds, next)
+				}
+			}
+		}
+	}
+
+	if len(lineEnds) > 0 {
+		return lineEnds
+	}
+	return neighbours
+}
+
+func huntShot(unknown []game.Coord, rng *rand.Rand) game.Coord {
+	parity := []game.Coord{}
+	for _, coord := range unknown {
+		if (coord.Row+coord.Col)%2 == 0 {
+			parity = append(parity, coord)
+		}
+	}
+	if len(parity) == 0 {
+		parity = unknown
+	}
+	return parity[rng.Intn(len(parity))]
+}
+
+// densityShot scores every unknown cell by how many ways the remaining ships
+// could cover it and shoots the most likely one. While a ship is damaged,
+// placements through its hits dominate the score.
+func densityShot(view boardView, unknown []game.Coord, rng *rand.Rand) game.Coord {
+	targeting := view.unresolvedHits()
+	scores := make(map[game.Coord]int, len(unknown))
+
+	for _, size := range view.remainingSizes() {
+		for row := 0; row < view.rules.Height; row++ {
+			for col := 0; col < view.rules.Width; col++ {
+				for _, dir := range directions[:2] {
+					cells := make([]game.Coord, 0, size)
+					valid := true
+					hits := 0
+					for i := 0; i < size; i++ {
+						coord := game.Coord{Row: row + dir.Row*i, Col: col + dir.Col*i}
+						if !view.rules.InBounds(coord) {
+							valid = false
+							break
+						}
+						switch view.cells[coord] {
+						case cellMiss, cellSunk:
+							valid = false
+						case cellHit:
+							hits++
+						}
+						if !valid {
+							break
+						}
+						cells = append(cells, coord)
+					}
+					if !valid {
+						continue
+					}
+
+					weight := 1
+					if targeting {
+						weight += 20 * hits
+					}
+					for _, coord := range cells {
+						if view.cells[coord] == cellUnknown {
+							scores[coord] += weight
+						}
+					}
+				}
+			}
+		}
+	}
+
+	best := 0
+	candidates := []game.Coord{}
+	for _, coord := range unknown {
+		score := scores[coord]
+		switch {
+		case score > best:
+			best = score
+			candidates = []game.Coord{coord}
+		case score == best && score > 0:
+			candidates = append(candidates, coord)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = unknown
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"shipsgame/internal/game"
+)
+
+func TestParseDifficulty(t *testing.T) {
+	if d, err := ParseDifficulty(""); err != nil || d != Medium {
+		t.Fatalf("expected medium default, got %s %v", d, err)
+	}
+	if _, err := ParseDifficulty("impossible"); err != ErrUnknownDifficulty {
+		t.Fatalf("expected unknown difficulty error, got %v", err)
+	}
+}
+
+func TestChooseShotNeverRepeats(t *testing.T) {
+	rules := game.SmallRuleset()
+	shots := map[string]string{}
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < rules.Width*rules.Height; i++ {
+		coord, err := ChooseShot(Easy, rules, shots, rng)
+		if err != nil {
+			t.Fatalf("choose shot: %v", err)
+		}
+		key := coordString(coord)
+		if _, ok := shots[key]; ok {
+			t.Fatalf("shot %s chosen twice", key)
+		}
+		shots[key] = "miss"
+	}
+
+	if _, err := ChooseShot(Easy, rules, shots, rng); err != ErrNoTargets {
+		t.Fatalf("expected no targets error, got %v", err)
+	}
+}
+
+func TestMediumTargetsAroundHit(t *testing.T) {
+	rules := game.ClassicRuleset()
+	shots := map[string]string{"4,4": "hit"}
+
+	for seed := int64(0); seed < 20; seed++ {
+		coord, err := ChooseShot(Medium, rules, shots, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("choose shot: %v", err)
+		}
+		if abs(coord.Row-4)+abs(coord.Col-4) != 1 {
+			t.Fatalf("expected neighbour of 4,4, got %v", coord)
+		}
+	}
+
+	shots["4,5"] = "hit"
+	for seed := int64(0); seed < 20; seed++ {
+		coord, _ := ChooseShot(Medium, rules, shots, rand.New(rand.NewSource(seed)))
+		if coord != (game.Coord{Row: 4, Col: 3}) && coord != (game.Coord{Row: 4, Col: 6}) {
+			t.Fatalf("expected line extension, got %v", coord)
+		}
+	}
+}
+
+func TestHardFollowsHits(t *testing.T) {
+	rules := game.ClassicRuleset()
+	shots := map[string]string{"0,0": "hit", "1,0": "miss"}
+
+	coord, err := ChooseShot(Hard, rules, shots, rand.New(rand.NewSource(3)))
+	if err != nil {
+		t.Fatalf("choose shot: %v", err)
+	}
+	if coord != (game.Coord{Row: 0, Col: 1}) {
+		t.Fatalf("expected 0,1 next to the hit, got %v", coord)
+	}
+}
+
+func TestChooseShotsDistinct(t *testing.T) {
+	coords, err := ChooseShots(Hard, game.ClassicRuleset(), map[string]string{}, 5, rand.New(rand.NewSource(9)))
+	if err != nil {
+		t.Fatalf("choose shots: %v", err)
+	}
+	seen := map[game.Coord]bool{}
+	for _, coord := range coords {
+		if seen[coord] {
+			t.Fatalf("duplicate shot %v", coord)
+		}
+		seen[coord] = true
+	}
+	if len(coords) != 5 {
+		t.Fatalf("expected 5 shots, got %d", len(coords))
+	}
+}
+
+func coordString(coord game.Coord) string {
+	return fmt.Sprintf("%d,%d", coord.Row, coord.Col)
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
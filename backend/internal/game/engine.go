@@ -1,8 +1,9 @@
 package game
 
-import "errors"
-
-const BoardSize = 10
+import (
+	"errors"
+	"sort"
+)
 
 type ShipType string
 
@@ -12,22 +13,18 @@ const (
 	Cruiser    ShipType = "cruiser"
 	Submarine  ShipType = "submarine"
 	Destroyer  ShipType = "destroyer"
+	PatrolBoat ShipType = "patrol_boat"
 )
 
-var StandardShipSet = map[ShipType]int{
-	Carrier:    5,
-	Battleship: 4,
-	Cruiser:    3,
-	Submarine:  3,
-	Destroyer:  2,
-}
-
 var (
-	ErrOutOfBounds      = errors.New("coordinate out of bounds")
-	ErrOverlap          = errors.New("ship placement overlaps existing ship")
+	ErrOutOfBounds       = errors.New("coordinate out of bounds")
+	ErrOverlap           = errors.New("ship placement overlaps existing ship")
+	ErrAdjacent          = errors.New("ship placement touches existing ship")
+	ErrInvalidShape      = errors.New("ship cells must form a straight line")
 	ErrShipAlreadyPlaced = errors.New("ship type already placed")
-	ErrUnknownShipType  = errors.New("unknown ship type")
-	ErrAlreadyShot      = errors.New("coordinate already shot")
+	ErrUnknownShipType   = errors.New("unknown ship type")
+	ErrAlreadyShot       = errors.New("coordinate already shot")
+	ErrInvalidSalvo      = errors.New("invalid number of shots in salvo")
 )
 
 type Orientation int
@@ -42,10 +39,6 @@ type Coord struct {
 	Col int
 }
 
-func (c Coord) InBounds() bool {
-	return c.Row >= 0 && c.Row < BoardSize && c.Col >= 0 && c.Col < BoardSize
-}
-
 type ShotOutcome int
 
 const (
@@ -71,13 +64,19 @@ func (s *Ship) isSunk() bool {
 }
 
 type Board struct {
+	rules    Ruleset
 	ships    map[ShipType]*Ship
 	occupied map[Coord]ShipType
 	shots    map[Coord]ShotOutcome
 }
 
 func NewBoard() *Board {
+	return NewBoardWithRules(ClassicRuleset())
+}
+
+func NewBoardWithRules(rules Ruleset) *Board {
 	return &Board{
+		rules:    rules,
 		ships:    make(map[ShipType]*Ship),
 		occupied: make(map[Coord]ShipType),
 		shots:    make(map[Coord]ShotOutcome),
@@ -85,13 +84,60 @@ func NewBoard() *Board {
 }
 
 func (b *Board) PlaceShip(shipType ShipType, start Coord, orientation Orientation) error {
+	cells, err := b.shipCells(shipType, start, orientation)
+	if err != nil {
+		return err
+	}
+	b.addShip(shipType, cells)
+	return nil
+}
+
+// PlaceShipCells places a ship given its cells in any order, as received from
+// clients.
+func (b *Board) PlaceShipCells(shipType ShipType, cells []Coord) error {
+	if len(cells) == 0 {
+		return ErrInvalidShape
+	}
+
+	sorted := make([]Coord, len(cells))
+	copy(sorted, cells)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Row != sorted[j].Row {
+			return sorted[i].Row < sorted[j].Row
+		}
+		return sorted[i].Col < sorted[j].Col
+	})
+
+	orientation := Horizontal
+	if len(sorted) > 1 && sorted[1].Col == sorted[0].Col {
+		orientation = Vertical
+	}
+
+	placed, err := b.shipCells(shipType, sorted[0], orientation)
+	if err != nil {
+		return err
+	}
+	if len(placed) != len(sorted) {
+		return ErrInvalidShape
+	}
+	for i := range placed {
+		if placed[i] != sorted[i] {
+			return ErrInvalidShape
+		}
+	}
+
+	b.addShip(shipType, placed)
+	return nil
+}
+
+func (b *Board) shipCells(shipType ShipType, start Coord, orientation Orientation) ([]Coord, error) {
 	if _, exists := b.ships[shipType]; exists {
-		return ErrShipAlreadyPlaced
+		return nil, ErrShipAlreadyPlaced
 	}
 
-	size, ok := StandardShipSet[shipType]
+	size, ok := b.rules.ShipSize(shipType)
 	if !ok {
-		return ErrUnknownShipType
+		return nil, ErrUnknownShipType
 	}
 
 	cells := make([]Coord, 0, size)
@@ -103,21 +149,31 @@ func (b *Board) PlaceShip(shipType ShipType, start Coord, orientation Orientatio
 		case Vertical:
 			coord.Row += i
 		default:
-			return ErrOutOfBounds
+			return nil, ErrOutOfBounds
 		}
 
-		if !coord.InBounds() {
-			return ErrOutOfBounds
+		if !b.rules.InBounds(coord) {
+			return nil, ErrOutOfBounds
 		}
 		if _, occupied := b.occupied[coord]; occupied {
-			return ErrOverlap
+			return nil, ErrOverlap
 		}
 		cells = append(cells, coord)
 	}
 
+	for _, coord := range cells {
+		if b.touchesShip(coord) {
+			return nil, ErrAdjacent
+		}
+	}
+
+	return cells, nil
+}
+
+func (b *Board) addShip(shipType ShipType, cells []Coord) {
 	ship := &Ship{
 		Type:  shipType,
-		Size:  size,
+		Size:  len(cells),
 		Cells: cells,
 		Hits:  make(map[Coord]bool),
 	}
@@ -126,12 +182,30 @@ func (b *Board) PlaceShip(shipType ShipType, start Coord, orientation Orientatio
 	for _, coord := range cells {
 		b.occupied[coord] = shipType
 	}
+}
 
-	return nil
+func (b *Board) touchesShip(coord Coord) bool {
+	if b.rules.Adjacency != AdjacencyNoEdge && b.rules.Adjacency != AdjacencyNoTouch {
+		return false
+	}
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if dr == 0 && dc == 0 {
+				continue
+			}
+			if dr != 0 && dc != 0 && b.rules.Adjacency == AdjacencyNoEdge {
+				continue
+			}
+			if _, occupied := b.occupied[Coord{Row: coord.Row + dr, Col: coord.Col + dc}]; occupied {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (b *Board) FireAt(coord Coord) (ShotResult, error) {
-	if !coord.InBounds() {
+	if !b.rules.InBounds(coord) {
 		return ShotResult{}, ErrOutOfBounds
 	}
 	if _, already := b.shots[coord]; already {
@@ -153,6 +227,49 @@ func (b *Board) FireAt(coord Coord) (ShotResult, error) {
 	return ShotResult{Outcome: ShotMiss}, nil
 }
 
+// FireSalvo fires every coordinate of a volley. The volley is validated as a
+// whole first, so an invalid coordinate leaves the board untouched.
+func (b *Board) FireSalvo(coords []Coord) ([]ShotResult, error) {
+	if len(coords) == 0 {
+		return nil, ErrInvalidSalvo
+	}
+
+	seen := make(map[Coord]bool, len(coords))
+	for _, coord := range coords {
+		if !b.rules.InBounds(coord) {
+			return nil, ErrOutOfBounds
+		}
+		if _, already := b.shots[coord]; already || seen[coord] {
+			return nil, ErrAlreadyShot
+		}
+		seen[coord] = true
+	}
+
+	results := make([]ShotResult, 0, len(coords))
+	for _, coord := range coords {
+		result, err := b.FireAt(coord)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (b *Board) SurvivingShips() int {
+	count := 0
+	for _, ship := range b.ships {
+		if !ship.isSunk() {
+			count++
+		}
+	}
+	return count
+}
+
+func (b *Board) Rules() Ruleset {
+	return b.rules
+}
+
 func (b *Board) AllShipsSunk() bool {
 	if len(b.ships) == 0 {
 		return false
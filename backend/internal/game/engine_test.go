@@ -142,3 +142,68 @@ func TestAllShipsSunk(t *testing.T) {
 		t.Fatalf("expected true when all ships sunk")
 	}
 }
+
+func TestFireSalvo(t *testing.T) {
+	board := NewBoardWithRules(SalvoRuleset())
+
+	if err := board.PlaceShip(Destroyer, Coord{Row: 0, Col: 0}, Horizontal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := board.FireSalvo(nil); err != ErrInvalidSalvo {
+		t.Fatalf("expected invalid salvo error, got %v", err)
+	}
+	if _, err := board.FireSalvo([]Coord{{Row: 0, Col: 0}, {Row: 0, Col: 0}}); err != ErrAlreadyShot {
+		t.Fatalf("expected already shot error for duplicate, got %v", err)
+	}
+	if _, err := board.FireSalvo([]Coord{{Row: 0, Col: 0}, {Row: 10, Col: 0}}); err != ErrOutOfBounds {
+		t.Fatalf("expected out of bounds error, got %v", err)
+	}
+	if board.SurvivingShips() != 1 {
+		t.Fatalf("expected rejected salvo to leave board untouched")
+	}
+
+	results, err := board.FireSalvo([]Coord{{Row: 5, Col: 5}, {Row: 0, Col: 0}, {Row: 0, Col: 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].Outcome != ShotMiss || results[1].Outcome != ShotHit || results[2].Outcome != ShotSunk {
+		t.Fatalf("unexpected outcomes: %+v", results)
+	}
+	if board.SurvivingShips() != 0 || !board.AllShipsSunk() {
+		t.Fatalf("expected all ships sunk")
+	}
+}
+
+func TestPlaceShipAdjacency(t *testing.T) {
+	noEdge := ClassicRuleset()
+	noEdge.Adjacency = AdjacencyNoEdge
+	noTouch := ClassicRuleset()
+	noTouch.Adjacency = AdjacencyNoTouch
+
+	cases := []struct {
+		name     string
+		rules    Ruleset
+		start    Coord
+		expected error
+	}{
+		{name: "allow edge", rules: ClassicRuleset(), start: Coord{Row: 1, Col: 0}, expected: nil},
+		{name: "no edge rejects edge", rules: noEdge, start: Coord{Row: 1, Col: 0}, expected: ErrAdjacent},
+		{name: "no edge allows diagonal", rules: noEdge, start: Coord{Row: 1, Col: 2}, expected: nil},
+		{name: "no touch rejects diagonal", rules: noTouch, start: Coord{Row: 1, Col: 2}, expected: ErrAdjacent},
+		{name: "no touch allows gap", rules: noTouch, start: Coord{Row: 2, Col: 0}, expected: nil},
+	}
+
+	for _, tc := range cases {
+		board := NewBoardWithRules(tc.rules)
+		if err := board.PlaceShip(Destroyer, Coord{Row: 0, Col: 0}, Horizontal); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if err := board.PlaceShip(Cruiser, tc.start, Horizontal); err != tc.expected {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.expected, err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+var ErrFleetPlacement = errors.New("could not place fleet")
+
+// FleetConstraints narrow the random placement. Fixed ships are kept exactly
+// where they are and the rest of the fleet is placed around them.
+type FleetConstraints struct {
+	Fixed      map[ShipType][]Coord
+	AvoidEdges bool
+	Spread     bool
+}
+
+const (
+	fleetAttempts    = 100
+	shipAttempts     = 200
+	spreadCandidates = 20
+)
+
+// RandomFleet returns a legal placement of the whole fleet for rules. The
+// result only depends on rng, so a seeded source gives a repeatable fleet.
+func RandomFleet(rules Ruleset, rng *rand.Rand, constraints FleetConstraints) (map[ShipType][]Coord, error) {
+	ids := make([]ShipType, 0, len(rules.ShipIDs()))
+	for _, id := range rules.ShipIDs() {
+		if _, fixed := constraints.Fixed[id]; !fixed {
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		left, _ := rules.ShipSize(ids[i])
+		right, _ := rules.ShipSize(ids[j])
+		return left > right
+	})
+
+	for attempt := 0; attempt < fleetAttempts; attempt++ {
+		board := NewBoardWithRules(rules)
+		for id, cells := range constraints.Fixed {
+			if err := board.PlaceShipCells(id, cells); err != nil {
+				return nil, err
+			}
+		}
+
+		placedAll := true
+		for _, id := range ids {
+			cells, ok := board.randomShipCells(id, rng, constraints)
+			if !ok {
+				placedAll = false
+				break
+			}
+			board.addShip(id, cells)
+		}
+		if placedAll {
+			return board.Ships(), nil
+		}
+	}
+	return nil, ErrFleetPlacement
+}
+
+func (b *Board) randomShipCells(id ShipType, rng *rand.Rand, constraints FleetConstraints) ([]Coord, bool) {
+	want := 1
+	if constraints.Spread {
+		want = spreadCandidates
+	}
+
+	var best []Coord
+	bestDistance := -1
+	found := 0
+	for try := 0; try < shipAttempts && found < want; try++ {
+		orientation := Orientation(rng.Intn(2))
+		start := Coord{Row: rng.Intn(b.rules.Height), Col: rng.Intn(b.rules.Width)}
+		cells, err := b.shipCells(id, start, orientation)
+		if err != nil {
+			continue
+		}
+		if constraints.AvoidEdges && b.touchesEdge(cells) {
+			continue
+		}
+
+		found++
+		distance := 0
+		if constraints.Spread {
+			distance = b.distanceToShips(cells)
+		}
+		if distance > bestDistance {
+			best = cells
+			bestDistance = distance
+		}
+	}
+	return best, best != nil
+}
+
+func (b *Board) touchesEdge(cells []Coord) bool {
+	for _, coord := range cells {
+		if coord.Row == 0 || coord.Col == 0 || coord.Row == b.rules.Height-1 || coord.Col == b.rules.Width-1 {
+			return true
+		}
+	}
+	return false
+}
+
+// distanceToShips is the smallest Chebyshev distance between cells and any
+// ship already on the board.
+func (b *Board) distanceToShips(cells []Coord) int {
+	best := b.rules.Width + b.rules.Height
+	for _, coord := range cells {
+		for occupied := range b.occupied {
+			distance := max(abs(coord.Row-occupied.Row), abs(coord.Col-occupied.Col))
+			if distance < best {
+				best = distance
+			}
+		}
+	}
+	return best
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
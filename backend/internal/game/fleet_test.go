@@ -0,0 +1,86 @@
+package game
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRandomFleetLegalAndSeeded(t *testing.T) {
+	rules := SmallRuleset()
+	rules.Adjacency = AdjacencyNoTouch
+
+	first, err := RandomFleet(rules, rand.New(rand.NewSource(7)), FleetConstraints{})
+	if err != nil {
+		t.Fatalf("random fleet: %v", err)
+	}
+	second, err := RandomFleet(rules, rand.New(rand.NewSource(7)), FleetConstraints{})
+	if err != nil {
+		t.Fatalf("random fleet: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("expected same seed to produce same fleet")
+	}
+	if len(first) != len(rules.ShipIDs()) {
+		t.Fatalf("expected %d ships, got %d", len(rules.ShipIDs()), len(first))
+	}
+
+	board := NewBoardWithRules(rules)
+	for id, cells := range first {
+		if err := board.PlaceShipCells(id, cells); err != nil {
+			t.Fatalf("placement of %s not legal: %v", id, err)
+		}
+	}
+}
+
+func TestRandomFleetKeepsFixedShips(t *testing.T) {
+	fixed := []Coord{{Row: 9, Col: 5}, {Row: 9, Col: 4}}
+	fleet, err := RandomFleet(ClassicRuleset(), rand.New(rand.NewSource(3)), FleetConstraints{
+		Fixed: map[ShipType][]Coord{Destroyer: fixed},
+	})
+	if err != nil {
+		t.Fatalf("random fleet: %v", err)
+	}
+	want := []Coord{{Row: 9, Col: 4}, {Row: 9, Col: 5}}
+	if !reflect.DeepEqual(fleet[Destroyer], want) {
+		t.Fatalf("expected destroyer kept at %v, got %v", want, fleet[Destroyer])
+	}
+	if len(fleet) != len(ClassicRuleset().ShipIDs()) {
+		t.Fatalf("expected full fleet, got %d ships", len(fleet))
+	}
+
+	_, err = RandomFleet(ClassicRuleset(), rand.New(rand.NewSource(3)), FleetConstraints{
+		Fixed: map[ShipType][]Coord{Destroyer: {{Row: 0, Col: 0}, {Row: 1, Col: 1}}},
+	})
+	if err != ErrInvalidShape {
+		t.Fatalf("expected invalid shape for diagonal fixed ship, got %v", err)
+	}
+}
+
+func TestRandomFleetAvoidEdges(t *testing.T) {
+	rules := ClassicRuleset()
+	for seed := int64(0); seed < 10; seed++ {
+		fleet, err := RandomFleet(rules, rand.New(rand.NewSource(seed)), FleetConstraints{AvoidEdges: true, Spread: true})
+		if err != nil {
+			t.Fatalf("random fleet: %v", err)
+		}
+		for id, cells := range fleet {
+			for _, coord := range cells {
+				if coord.Row == 0 || coord.Col == 0 || coord.Row == rules.Height-1 || coord.Col == rules.Width-1 {
+					t.Fatalf("seed %d: %s touches the edge at %v", seed, id, coord)
+				}
+			}
+		}
+	}
+}
+
+func TestPlaceShipCellsUnordered(t *testing.T) {
+	board := NewBoard()
+
+	if err := board.PlaceShipCells(Cruiser, []Coord{{Row: 4, Col: 2}, {Row: 2, Col: 2}, {Row: 3, Col: 2}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := board.PlaceShipCells(Destroyer, []Coord{{Row: 0, Col: 0}, {Row: 0, Col: 2}}); err != ErrInvalidShape {
+		t.Fatalf("expected invalid shape for gap, got %v", err)
+	}
+}
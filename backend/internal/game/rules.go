@@ -0,0 +1,211 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	RulesetClassic           = "classic"
+	RulesetMiltonBradley1990 = "milton_bradley_1990"
+	RulesetSmall             = "small_8x8"
+	RulesetSalvo             = "salvo"
+)
+
+var (
+	ErrUnknownRuleset = errors.New("unknown ruleset")
+	ErrInvalidRuleset = errors.New("invalid ruleset")
+)
+
+// AdjacencyRule controls whether ships may touch. AdjacencyNoEdge forbids
+// ships sharing an edge, AdjacencyNoTouch also forbids diagonal contact.
+type AdjacencyRule string
+
+const (
+	AdjacencyAllow   AdjacencyRule = "allow"
+	AdjacencyNoEdge  AdjacencyRule = "no_edge"
+	AdjacencyNoTouch AdjacencyRule = "no_touch"
+)
+
+// SalvoMode decides how many shots a player fires per turn. SalvoNone is the
+// classic one shot per turn, SalvoFixed uses ShotsPerTurn and SalvoSurvivors
+// allows one shot per surviving ship of the shooter.
+type SalvoMode string
+
+const (
+	SalvoNone      SalvoMode = ""
+	SalvoFixed     SalvoMode = "fixed"
+	SalvoSurvivors SalvoMode = "survivors"
+)
+
+type ShipSpec struct {
+	Type  ShipType `json:"type"`
+	Size  int      `json:"size"`
+	Count int      `json:"count"`
+}
+
+type Ruleset struct {
+	Name         string        `json:"name"`
+	Width        int           `json:"width"`
+	Height       int           `json:"height"`
+	Fleet        []ShipSpec    `json:"fleet"`
+	Adjacency    AdjacencyRule `json:"adjacency"`
+	ShotsPerTurn int           `json:"shots_per_turn"`
+	Salvo        SalvoMode     `json:"salvo,omitempty"`
+}
+
+func ClassicRuleset() Ruleset {
+	return Ruleset{
+		Name:   RulesetClassic,
+		Width:  10,
+		Height: 10,
+		Fleet: []ShipSpec{
+			{Type: Carrier, Size: 5, Count: 1},
+			{Type: Battleship, Size: 4, Count: 1},
+			{Type: Cruiser, Size: 3, Count: 1},
+			{Type: Submarine, Size: 3, Count: 1},
+			{Type: Destroyer, Size: 2, Count: 1},
+		},
+		Adjacency:    AdjacencyAllow,
+		ShotsPerTurn: 1,
+	}
+}
+
+func MiltonBradley1990Ruleset() Ruleset {
+	return Ruleset{
+		Name:   RulesetMiltonBradley1990,
+		Width:  10,
+		Height: 10,
+		Fleet: []ShipSpec{
+			{Type: Carrier, Size: 5, Count: 1},
+			{Type: Battleship, Size: 4, Count: 1},
+			{Type: Destroyer, Size: 3, Count: 1},
+			{Type: Submarine, Size: 3, Count: 1},
+			{Type: PatrolBoat, Size: 2, Count: 1},
+		},
+		Adjacency:    AdjacencyAllow,
+		ShotsPerTurn: 1,
+	}
+}
+
+func SmallRuleset() Ruleset {
+	return Ruleset{
+		Name:   RulesetSmall,
+		Width:  8,
+		Height: 8,
+		Fleet: []ShipSpec{
+			{Type: Battleship, Size: 4, Count: 1},
+			{Type: Cruiser, Size: 3, Count: 1},
+			{Type: Destroyer, Size: 2, Count: 2},
+		},
+		Adjacency:    AdjacencyAllow,
+		ShotsPerTurn: 1,
+	}
+}
+
+func SalvoRuleset() Ruleset {
+	rules := ClassicRuleset()
+	rules.Name = RulesetSalvo
+	rules.Salvo = SalvoSurvivors
+	return rules
+}
+
+func LookupRuleset(name string) (Ruleset, error) {
+	switch name {
+	case "", RulesetClassic:
+		return ClassicRuleset(), nil
+	case RulesetMiltonBradley1990:
+		return MiltonBradley1990Ruleset(), nil
+	case RulesetSmall:
+		return SmallRuleset(), nil
+	case RulesetSalvo:
+		return SalvoRuleset(), nil
+	default:
+		return Ruleset{}, ErrUnknownRuleset
+	}
+}
+
+func (r Ruleset) Validate() error {
+	if r.Width <= 0 || r.Height <= 0 || len(r.Fleet) == 0 || r.ShotsPerTurn < 1 {
+		return ErrInvalidRuleset
+	}
+	switch r.Adjacency {
+	case AdjacencyAllow, AdjacencyNoEdge, AdjacencyNoTouch:
+	default:
+		return ErrInvalidRuleset
+	}
+	switch r.Salvo {
+	case SalvoNone:
+		if r.ShotsPerTurn != 1 {
+			return ErrInvalidRuleset
+		}
+	case SalvoFixed, SalvoSurvivors:
+		if r.ShotsPerTurn > r.Width*r.Height {
+			return ErrInvalidRuleset
+		}
+	default:
+		return ErrInvalidRuleset
+	}
+
+	seen := make(map[ShipType]bool, len(r.Fleet))
+	for _, spec := range r.Fleet {
+		if spec.Type == "" || seen[spec.Type] || spec.Count < 1 || spec.Size < 1 {
+			return ErrInvalidRuleset
+		}
+		if spec.Size > r.Width && spec.Size > r.Height {
+			return ErrInvalidRuleset
+		}
+		seen[spec.Type] = true
+	}
+	return nil
+}
+
+func (r Ruleset) IsSalvo() bool {
+	return r.Salvo != SalvoNone
+}
+
+func (r Ruleset) SalvoSize(survivingShips int) int {
+	switch r.Salvo {
+	case SalvoFixed:
+		return r.ShotsPerTurn
+	case SalvoSurvivors:
+		return survivingShips
+	default:
+		return 1
+	}
+}
+
+func (r Ruleset) InBounds(c Coord) bool {
+	return c.Row >= 0 && c.Row < r.Height && c.Col >= 0 && c.Col < r.Width
+}
+
+// ShipIDs lists every ship in the fleet. The first ship of a type is keyed by
+// the type itself; additional ships of the same type get a numeric suffix
+// (destroyer, destroyer_2, ...).
+func (r Ruleset) ShipIDs() []ShipType {
+	ids := []ShipType{}
+	for _, spec := range r.Fleet {
+		for n := 1; n <= spec.Count; n++ {
+			ids = append(ids, ShipID(spec.Type, n))
+		}
+	}
+	return ids
+}
+
+func (r Ruleset) ShipSize(id ShipType) (int, bool) {
+	for _, spec := range r.Fleet {
+		for n := 1; n <= spec.Count; n++ {
+			if ShipID(spec.Type, n) == id {
+				return spec.Size, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func ShipID(shipType ShipType, n int) ShipType {
+	if n <= 1 {
+		return shipType
+	}
+	return ShipType(fmt.Sprintf("%s_%d", shipType, n))
+}
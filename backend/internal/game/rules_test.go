@@ -0,0 +1,86 @@
+package game
+
+import "testing"
+
+func TestLookupRuleset(t *testing.T) {
+	for _, name := range []string{"", RulesetClassic, RulesetMiltonBradley1990, RulesetSmall} {
+		rules, err := LookupRuleset(name)
+		if err != nil {
+			t.Fatalf("lookup %q: %v", name, err)
+		}
+		if err := rules.Validate(); err != nil {
+			t.Fatalf("preset %q invalid: %v", rules.Name, err)
+		}
+	}
+
+	if _, err := LookupRuleset("nope"); err != ErrUnknownRuleset {
+		t.Fatalf("expected unknown ruleset error, got %v", err)
+	}
+}
+
+func TestRulesetValidate(t *testing.T) {
+	rules := ClassicRuleset()
+	rules.Width = 0
+	if err := rules.Validate(); err != ErrInvalidRuleset {
+		t.Fatalf("expected invalid ruleset for zero width, got %v", err)
+	}
+
+	rules = ClassicRuleset()
+	rules.Fleet = append(rules.Fleet, ShipSpec{Type: Carrier, Size: 5, Count: 1})
+	if err := rules.Validate(); err != ErrInvalidRuleset {
+		t.Fatalf("expected invalid ruleset for duplicate ship type, got %v", err)
+	}
+
+	rules = ClassicRuleset()
+	rules.Salvo = SalvoFixed
+	rules.ShotsPerTurn = 3
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("expected fixed salvo valid, got %v", err)
+	}
+	rules.ShotsPerTurn = 101
+	if err := rules.Validate(); err != ErrInvalidRuleset {
+		t.Fatalf("expected invalid ruleset for more shots than cells, got %v", err)
+	}
+}
+
+func TestRulesetShipIDs(t *testing.T) {
+	rules := SmallRuleset()
+
+	ids := rules.ShipIDs()
+	want := []ShipType{Battleship, Cruiser, Destroyer, "destroyer_2"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, ids)
+		}
+	}
+
+	if size, ok := rules.ShipSize("destroyer_2"); !ok || size != 2 {
+		t.Fatalf("expected destroyer_2 size 2, got %d %v", size, ok)
+	}
+	if _, ok := rules.ShipSize("destroyer_3"); ok {
+		t.Fatalf("expected destroyer_3 to be unknown")
+	}
+}
+
+func TestSmallBoardBounds(t *testing.T) {
+	board := NewBoardWithRules(SmallRuleset())
+
+	if err := board.PlaceShip(Battleship, Coord{Row: 0, Col: 5}, Horizontal); err != ErrOutOfBounds {
+		t.Fatalf("expected out of bounds error, got %v", err)
+	}
+	if err := board.PlaceShip(Battleship, Coord{Row: 0, Col: 4}, Horizontal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := board.PlaceShip(Carrier, Coord{Row: 2, Col: 0}, Horizontal); err != ErrUnknownShipType {
+		t.Fatalf("expected unknown ship error, got %v", err)
+	}
+	if err := board.PlaceShip("destroyer_2", Coord{Row: 4, Col: 0}, Vertical); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := board.FireAt(Coord{Row: 8, Col: 0}); err != ErrOutOfBounds {
+		t.Fatalf("expected out of bounds error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+	"shipsgame/internal/store/postgres"
+)
+
+const (
+	maxDisplayNameLength = 32
+	defaultUserTokenTTL  = 30 * 24 * time.Hour
+)
+
+type UserStore interface {
+	CreateUser(ctx context.Context, displayName string) (postgres.User, error)
+}
+
+// AuthHandler signs up guests. A guest is a users row with a display name and
+// no credentials; the returned user token is what identifies them afterwards.
+type AuthHandler struct {
+	Users     UserStore
+	JWTSecret string
+	TokenTTL  time.Duration
+	Logger    *log.Logger
+}
+
+type GuestRequest struct {
+	DisplayName string `json:"display_name"`
+}
+
+type GuestResponse struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Token       string `json:"token"`
+}
+
+func (h *AuthHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/auth/guest", h.handleGuest)
+}
+
+func (h *AuthHandler) handleGuest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.JWTSecret == "" {
+		writeError(w, http.StatusInternalServerError, "missing JWT secret")
+		return
+	}
+	if h.Users == nil {
+		writeError(w, http.StatusServiceUnavailable, "user store unavailable")
+		return
+	}
+
+	var req GuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" {
+		name = guestName()
+	}
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		writeError(w, http.StatusBadRequest, "display_name too long")
+		return
+	}
+
+	user, err := h.Users.CreateUser(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create user")
+		return
+	}
+
+	ttl := h.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultUserTokenTTL
+	}
+	token, err := auth.SignUserToken(h.JWTSecret, auth.UserClaims{
+		UserID:      user.ID,
+		DisplayName: user.DisplayName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to sign token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GuestResponse{
+		UserID:      user.ID,
+		DisplayName: user.DisplayName,
+		Token:       token,
+	})
+
+	if h.Logger != nil {
+		h.Logger.Printf("guest created user_id=%s", user.ID)
+	}
+}
+
+func guestName() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "Guest"
+	}
+	return fmt.Sprintf("Guest %04d", n.Int64())
+}
+
+func bearerUser(r *http.Request, secret string) (auth.UserClaims, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return auth.UserClaims{}, err
+	}
+	return auth.ParseUserToken(token, secret)
+}
@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shipsgame/internal/auth"
+	"shipsgame/internal/store/postgres"
+)
+
+type fakeUserStore struct {
+	names []string
+}
+
+func (f *fakeUserStore) CreateUser(_ context.Context, displayName string) (postgres.User, error) {
+	f.names = append(f.names, displayName)
+	return postgres.User{ID: "user-1", DisplayName: displayName, CreatedAt: time.Now()}, nil
+}
+
+func TestGuestSignUp(t *testing.T) {
+	users := &fakeUserStore{}
+	handler := &AuthHandler{Users: users, JWTSecret: "secret"}
+	mux := http.NewServeMux()
+	handler.Register(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/guest", strings.NewReader(`{"display_name":"  Ada  "}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp GuestResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.UserID != "user-1" || resp.DisplayName != "Ada" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	claims, err := auth.ParseUserToken(resp.Token, "secret")
+	if err != nil {
+		t.Fatalf("parse user token: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.ExpiresAt.Before(time.Now().Add(7*24*time.Hour)) {
+		t.Fatalf("expected long-lived token for user-1, got %+v", claims)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/guest", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.HasPrefix(users.names[1], "Guest ") {
+		t.Fatalf("expected generated guest name, got %d %v", rec.Code, users.names)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/guest", strings.NewReader(`{"display_name":"`+strings.Repeat("a", 33)+`"}`))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for long name, got %d", rec.Code)
+	}
+}
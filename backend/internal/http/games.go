@@ -3,19 +3,52 @@ package httpapi
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"shipsgame/internal/auth"
+	"shipsgame/internal/bot"
+	"shipsgame/internal/game"
 	redisstore "shipsgame/internal/store/redis"
 )
 
 type GamesHandler struct {
 	Store     *redisstore.Client
+	Bot       *bot.Player
 	JWTSecret string
 	Logger    *log.Logger
+
+	// LobbyTTL hides public games that have waited longer than this from
+	// the lobby. Zero lists them until they start or expire.
+	LobbyTTL time.Duration
+
+	// OnFinished is called after a REST request ends a game so connected
+	// players can be told.
+	OnFinished func(meta redisstore.GameMeta)
+}
+
+type CreateGameRequest struct {
+	Ruleset    string `json:"ruleset"`
+	Adjacency  string `json:"adjacency"`
+	Opponent   string `json:"opponent"`
+	Difficulty string `json:"difficulty"`
+
+	// ShotsPerTurn turns the ruleset into a fixed salvo of that many shots.
+	ShotsPerTurn int `json:"shots_per_turn"`
+
+	TurnSeconds   int    `json:"turn_seconds"`
+	TimeoutAction string `json:"timeout_action"`
+
+	TimeBankSeconds  int `json:"time_bank_seconds"`
+	IncrementSeconds int `json:"increment_seconds"`
+
+	Visibility string `json:"visibility"`
+
+	RevealDelaySeconds int `json:"reveal_delay_seconds"`
 }
 
 type CreateGameResponse struct {
@@ -23,6 +56,26 @@ type CreateGameResponse struct {
 	JoinCode string `json:"join_code"`
 	Player   string `json:"player"`
 	Token    string `json:"token"`
+	Ruleset  string `json:"ruleset"`
+	Opponent string `json:"opponent"`
+}
+
+type LobbyResponse struct {
+	Games []LobbyGameResponse `json:"games"`
+}
+
+type LobbyGameResponse struct {
+	GameID    string    `json:"game_id"`
+	JoinCode  string    `json:"join_code"`
+	Ruleset   string    `json:"ruleset"`
+	HostName  string    `json:"host_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SpectateGameResponse struct {
+	GameID        string `json:"game_id"`
+	Token         string `json:"token"`
+	RevealDelayMs int64  `json:"reveal_delay_ms,omitempty"`
 }
 
 type JoinGameRequest struct {
@@ -35,9 +88,19 @@ type JoinGameResponse struct {
 	Token  string `json:"token"`
 }
 
+type ResignGameResponse struct {
+	GameID string `json:"game_id"`
+	Status string `json:"status"`
+	Winner string `json:"winner"`
+	Reason string `json:"reason"`
+}
+
 func (h *GamesHandler) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/games", h.handleCreate)
 	mux.HandleFunc("/games/join", h.handleJoin)
+	mux.HandleFunc("/games/{id}/resign", h.handleResign)
+	mux.HandleFunc("/games/{id}/spectate", h.handleSpectate)
+	mux.HandleFunc("/lobby", h.handleLobby)
 }
 
 func (h *GamesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
@@ -49,16 +112,121 @@ func (h *GamesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "missing JWT secret")
 		return
 	}
+	user, err := bearerUser(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateGameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	rules, err := game.LookupRuleset(req.Ruleset)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unknown ruleset")
+		return
+	}
+	if req.Adjacency != "" {
+		rules.Adjacency = game.AdjacencyRule(req.Adjacency)
+		if err := rules.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid adjacency")
+			return
+		}
+	}
+	if req.ShotsPerTurn < 0 {
+		writeError(w, http.StatusBadRequest, "invalid shots_per_turn")
+		return
+	}
+	if req.ShotsPerTurn > 0 {
+		rules.Salvo = game.SalvoFixed
+		rules.ShotsPerTurn = req.ShotsPerTurn
+		if err := rules.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid shots_per_turn")
+			return
+		}
+	}
+
+	if req.TurnSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "invalid turn_seconds")
+		return
+	}
+	switch req.TimeoutAction {
+	case "", redisstore.TimeoutSkip, redisstore.TimeoutRandomShot:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid timeout_action")
+		return
+	}
+
+	if req.TimeBankSeconds < 0 || req.IncrementSeconds < 0 || (req.IncrementSeconds > 0 && req.TimeBankSeconds == 0) {
+		writeError(w, http.StatusBadRequest, "invalid time control")
+		return
+	}
+	if req.RevealDelaySeconds < 0 {
+		writeError(w, http.StatusBadRequest, "invalid reveal_delay_seconds")
+		return
+	}
 
-	meta, err := h.Store.CreateGame(r.Context())
+	opts := redisstore.GameOptions{
+		Ruleset:       rules,
+		TurnTimeout:   time.Duration(req.TurnSeconds) * time.Second,
+		TimeoutAction: req.TimeoutAction,
+		TimeBank:      time.Duration(req.TimeBankSeconds) * time.Second,
+		Increment:     time.Duration(req.IncrementSeconds) * time.Second,
+		P1UserID:      user.UserID,
+		P1Name:        user.DisplayName,
+		RevealDelay:   time.Duration(req.RevealDelaySeconds) * time.Second,
+	}
+	switch req.Visibility {
+	case "", redisstore.VisibilityPrivate, redisstore.VisibilityPublic:
+		opts.Visibility = req.Visibility
+	default:
+		writeError(w, http.StatusBadRequest, "invalid visibility")
+		return
+	}
+	switch req.Opponent {
+	case "", redisstore.OpponentHuman:
+		opts.Opponent = redisstore.OpponentHuman
+	case redisstore.OpponentAI:
+		if h.Bot == nil {
+			writeError(w, http.StatusBadRequest, "ai opponent unavailable")
+			return
+		}
+		difficulty, err := bot.ParseDifficulty(req.Difficulty)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "unknown difficulty")
+			return
+		}
+		if opts.Visibility == redisstore.VisibilityPublic {
+			writeError(w, http.StatusBadRequest, "ai games cannot be public")
+			return
+		}
+		opts.Opponent = redisstore.OpponentAI
+		opts.Difficulty = string(difficulty)
+	default:
+		writeError(w, http.StatusBadRequest, "unknown opponent")
+		return
+	}
+
+	meta, err := h.Store.CreateGame(r.Context(), opts)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to create game")
 		return
 	}
 
+	if meta.Opponent == redisstore.OpponentAI {
+		if err := h.Bot.PlaceFleet(r.Context(), meta.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to place ai fleet")
+			return
+		}
+	}
+
 	claims := auth.Claims{
 		GameID: meta.ID,
 		Player: "p1",
+		UserID: user.UserID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 		},
@@ -75,10 +243,12 @@ func (h *GamesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		JoinCode: meta.JoinCode,
 		Player:   "p1",
 		Token:    token,
+		Ruleset:  meta.Rules.Name,
+		Opponent: meta.Opponent,
 	})
 
 	if h.Logger != nil {
-		h.Logger.Printf("game created game_id=%s join_code=%s player=p1", meta.ID, meta.JoinCode)
+		h.Logger.Printf("game created game_id=%s join_code=%s player=p1 user_id=%s ruleset=%s opponent=%s", meta.ID, meta.JoinCode, user.UserID, meta.Rules.Name, meta.Opponent)
 	}
 }
 
@@ -91,6 +261,11 @@ func (h *GamesHandler) handleJoin(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "missing JWT secret")
 		return
 	}
+	user, err := bearerUser(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
 
 	var req JoinGameRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -102,13 +277,17 @@ func (h *GamesHandler) handleJoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	meta, player, err := h.Store.JoinGame(r.Context(), req.JoinCode)
+	meta, player, err := h.Store.JoinGame(r.Context(), req.JoinCode, user.UserID)
 	if err != nil {
 		switch {
 		case errors.Is(err, redisstore.ErrInvalidJoinCode):
 			writeError(w, http.StatusBadRequest, "invalid join code")
 		case errors.Is(err, redisstore.ErrGameFull):
 			writeError(w, http.StatusConflict, "game full")
+		case errors.Is(err, redisstore.ErrOwnGame):
+			writeError(w, http.StatusConflict, "cannot join own game")
+		case errors.Is(err, redisstore.ErrGameNotJoinable):
+			writeError(w, http.StatusConflict, "game not joinable")
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to join game")
 		}
@@ -118,6 +297,7 @@ func (h *GamesHandler) handleJoin(w http.ResponseWriter, r *http.Request) {
 	claims := auth.Claims{
 		GameID: meta.ID,
 		Player: player,
+		UserID: user.UserID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 		},
@@ -136,8 +316,163 @@ func (h *GamesHandler) handleJoin(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if h.Logger != nil {
-		h.Logger.Printf("game joined game_id=%s player=%s", meta.ID, player)
+		h.Logger.Printf("game joined game_id=%s player=%s user_id=%s", meta.ID, player, user.UserID)
+	}
+}
+
+func (h *GamesHandler) handleResign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.JWTSecret == "" {
+		writeError(w, http.StatusInternalServerError, "missing JWT secret")
+		return
+	}
+
+	claims, err := bearerClaims(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	gameID := r.PathValue("id")
+	if claims.GameID != gameID || claims.IsSpectator() {
+		writeError(w, http.StatusForbidden, "token not valid for game")
+		return
+	}
+
+	meta, err := h.Store.Resign(r.Context(), gameID, claims.Player)
+	if err != nil {
+		switch {
+		case errors.Is(err, redisstore.ErrGameNotFound):
+			writeError(w, http.StatusNotFound, "game not found")
+		case errors.Is(err, redisstore.ErrGameFinished):
+			writeError(w, http.StatusConflict, "game already finished")
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to resign game")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ResignGameResponse{
+		GameID: meta.ID,
+		Status: meta.Status,
+		Winner: meta.Winner,
+		Reason: meta.FinishReason,
+	})
+
+	if h.OnFinished != nil {
+		h.OnFinished(meta)
+	}
+	if h.Logger != nil {
+		h.Logger.Printf("game resigned game_id=%s player=%s reason=%s", meta.ID, claims.Player, meta.FinishReason)
+	}
+}
+
+// handleSpectate issues a read-only token for watching a game. Players of the
+// game are refused so they can't watch their opponent's fleet in a game with
+// a reveal delay.
+func (h *GamesHandler) handleSpectate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.JWTSecret == "" {
+		writeError(w, http.StatusInternalServerError, "missing JWT secret")
+		return
+	}
+	user, err := bearerUser(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	meta, err := h.Store.GetMeta(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, redisstore.ErrGameNotFound) {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to load game")
+		return
+	}
+	if user.UserID == meta.P1UserID || user.UserID == meta.P2UserID {
+		writeError(w, http.StatusForbidden, "players cannot spectate their own game")
+		return
+	}
+
+	token, err := auth.SignToken(h.JWTSecret, auth.Claims{
+		GameID: meta.ID,
+		UserID: user.UserID,
+		Role:   auth.RoleSpectator,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+		},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to sign token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SpectateGameResponse{
+		GameID:        meta.ID,
+		Token:         token,
+		RevealDelayMs: meta.RevealDelay.Milliseconds(),
+	})
+
+	if h.Logger != nil {
+		h.Logger.Printf("spectator joined game_id=%s user_id=%s", meta.ID, user.UserID)
+	}
+}
+
+func (h *GamesHandler) handleLobby(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := queryInt(r, "limit", defaultPageLimit)
+	if !ok || limit < 1 || limit > maxPageLimit {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+
+	entries, err := h.Store.ListLobby(r.Context(), int64(limit), h.LobbyTTL)
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Printf("lobby query failed err=%v", err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to load lobby")
+		return
+	}
+
+	resp := LobbyResponse{Games: make([]LobbyGameResponse, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Games = append(resp.Games, LobbyGameResponse{
+			GameID:    entry.GameID,
+			JoinCode:  entry.JoinCode,
+			Ruleset:   entry.Ruleset,
+			HostName:  entry.HostName,
+			CreatedAt: entry.CreatedAt.UTC(),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func bearerClaims(r *http.Request, secret string) (auth.Claims, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	return auth.ParseToken(token, secret)
+}
+
+func bearerToken(r *http.Request) (string, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", errors.New("invalid authorization header")
 	}
+	return token, nil
 }
 
 func writeJSON(w http.ResponseWriter, status int, payload any) {
@@ -0,0 +1,300 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func newTestGamesHandler(t *testing.T) (*GamesHandler, *http.ServeMux) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	t.Cleanup(func() { _ = store.Close() })
+
+	handler := &GamesHandler{Store: store, JWTSecret: "secret"}
+	mux := http.NewServeMux()
+	handler.Register(mux)
+	return handler, mux
+}
+
+func signTestToken(t *testing.T, gameID, player string) string {
+	token, err := auth.SignToken("secret", auth.Claims{
+		GameID: gameID,
+		Player: player,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func signTestUserToken(t *testing.T, userID string) string {
+	token, err := auth.SignUserToken("secret", auth.UserClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func postJSON(mux *http.ServeMux, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateAndJoinRequireUser(t *testing.T) {
+	handler, mux := newTestGamesHandler(t)
+
+	if rec := postJSON(mux, "/games", `{}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/games", `{}`, signTestToken(t, "game", "p1")); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for game token, got %d", rec.Code)
+	}
+
+	rec := postJSON(mux, "/games", `{}`, signTestUserToken(t, "user-1"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateGameResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	claims, err := auth.ParseToken(created.Token, "secret")
+	if err != nil || claims.UserID != "user-1" {
+		t.Fatalf("expected game token for user-1, got %+v, %v", claims, err)
+	}
+
+	body := `{"join_code":"` + created.JoinCode + `"}`
+	if rec := postJSON(mux, "/games/join", body, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/games/join", body, signTestUserToken(t, "user-1")); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 joining own game, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/games/join", body, signTestUserToken(t, "user-2")); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	meta, err := handler.Store.GetMeta(context.Background(), created.GameID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if meta.P1UserID != "user-1" || meta.P2UserID != "user-2" {
+		t.Fatalf("expected seats bound to users, got %q and %q", meta.P1UserID, meta.P2UserID)
+	}
+}
+
+func TestResignGame(t *testing.T) {
+	handler, mux := newTestGamesHandler(t)
+
+	meta, err := handler.Store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	var notified redisstore.GameMeta
+	handler.OnFinished = func(meta redisstore.GameMeta) { notified = meta }
+
+	req := httptest.NewRequest(http.MethodPost, "/games/"+meta.ID+"/resign", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestToken(t, "other-game", "p1"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for foreign token, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/games/"+meta.ID+"/resign", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestToken(t, meta.ID, "p1"))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp ResignGameResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != "finished" || resp.Reason != redisstore.FinishAbandoned {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if notified.ID != meta.ID {
+		t.Fatalf("expected finish callback for %s, got %+v", meta.ID, notified)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/games/"+meta.ID+"/resign", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestToken(t, meta.ID, "p2"))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on finished game, got %d", rec.Code)
+	}
+
+	handler.JWTSecret = ""
+	req = httptest.NewRequest(http.MethodPost, "/games/"+meta.ID+"/resign", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestToken(t, meta.ID, "p2"))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 without a JWT secret, got %d", rec.Code)
+	}
+}
+
+func TestLobbyListsPublicGames(t *testing.T) {
+	_, mux := newTestGamesHandler(t)
+
+	host, err := auth.SignUserToken("secret", auth.UserClaims{
+		UserID:      "user-1",
+		DisplayName: "Ada",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if rec := postJSON(mux, "/games", `{"visibility":"friends"}`, host); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown visibility, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/games", `{}`, host); rec.Code != http.StatusOK {
+		t.Fatalf("expected private game created, got %d", rec.Code)
+	}
+	rec := postJSON(mux, "/games", `{"visibility":"public","ruleset":"classic"}`, host)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateGameResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+
+	lobby := func() LobbyResponse {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lobby", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp LobbyResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode lobby: %v", err)
+		}
+		return resp
+	}
+
+	resp := lobby()
+	if len(resp.Games) != 1 {
+		t.Fatalf("expected one public game, got %+v", resp.Games)
+	}
+	entry := resp.Games[0]
+	if entry.GameID != created.GameID || entry.JoinCode != created.JoinCode || entry.HostName != "Ada" || entry.Ruleset != created.Ruleset {
+		t.Fatalf("unexpected lobby entry %+v", entry)
+	}
+
+	join := postJSON(mux, "/games/join", `{"join_code":"`+created.JoinCode+`"}`, signTestUserToken(t, "user-2"))
+	if join.Code != http.StatusOK {
+		t.Fatalf("expected join 200, got %d", join.Code)
+	}
+	if resp := lobby(); len(resp.Games) != 0 {
+		t.Fatalf("expected lobby empty after join, got %+v", resp.Games)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lobby?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid limit, got %d", rec.Code)
+	}
+}
+
+func TestSpectateIssuesReadOnlyToken(t *testing.T) {
+	_, mux := newTestGamesHandler(t)
+
+	host := signTestUserToken(t, "user-1")
+	rec := postJSON(mux, "/games", `{"reveal_delay_seconds":120}`, host)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateGameResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+
+	path := "/games/" + created.GameID + "/spectate"
+	if rec := postJSON(mux, path, ``, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, path, ``, host); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for the host, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/games/missing/spectate", ``, signTestUserToken(t, "user-2")); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown game, got %d", rec.Code)
+	}
+
+	rec = postJSON(mux, path, ``, signTestUserToken(t, "user-2"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SpectateGameResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode spectate: %v", err)
+	}
+	if resp.GameID != created.GameID || resp.RevealDelayMs != 120000 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	claims, err := auth.ParseToken(resp.Token, "secret")
+	if err != nil || !claims.IsSpectator() || claims.GameID != created.GameID {
+		t.Fatalf("expected spectator token, got %+v %v", claims, err)
+	}
+
+	if rec := postJSON(mux, "/games/"+created.GameID+"/resign", ``, resp.Token); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected spectators unable to resign, got %d", rec.Code)
+	}
+}
+
+func TestCreateFixedSalvoGame(t *testing.T) {
+	handler, mux := newTestGamesHandler(t)
+	token := signTestUserToken(t, "user-1")
+
+	for _, body := range []string{`{"shots_per_turn": -1}`, `{"shots_per_turn": 101}`} {
+		if rec := postJSON(mux, "/games", body, token); rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+
+	rec := postJSON(mux, "/games", `{"shots_per_turn": 3}`, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created CreateGameResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	meta, err := handler.Store.GetMeta(context.Background(), created.GameID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if meta.Rules.Salvo != game.SalvoFixed || meta.Rules.ShotsPerTurn != 3 {
+		t.Fatalf("expected a fixed salvo of 3, got %+v", meta.Rules)
+	}
+}
@@ -0,0 +1,271 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"shipsgame/internal/store/postgres"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type HistoryStore interface {
+	GetLeaderboard(ctx context.Context, limit int, offset int) ([]postgres.LeaderboardEntry, error)
+	GetGameSummary(ctx context.Context, gameID string) (postgres.GameSummary, error)
+	GetGameEvents(ctx context.Context, gameID string) ([]postgres.GameEvent, error)
+	GetUser(ctx context.Context, userID string) (postgres.User, error)
+	ListUserGames(ctx context.Context, userID string, after *postgres.GameCursor, limit int) ([]postgres.GameSummary, *postgres.GameCursor, error)
+}
+
+// HistoryHandler serves archived games and the leaderboard from Postgres.
+// Games still being played are not in the archive yet and return 404.
+type HistoryHandler struct {
+	Store  HistoryStore
+	Logger *log.Logger
+}
+
+type LeaderboardResponse struct {
+	Entries []LeaderboardEntryResponse `json:"entries"`
+	Limit   int                        `json:"limit"`
+	Offset  int                        `json:"offset"`
+}
+
+type LeaderboardEntryResponse struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Rating      int    `json:"rating"`
+	Wins        int    `json:"wins"`
+	Losses      int    `json:"losses"`
+	TotalGames  int    `json:"total_games"`
+}
+
+type GameSummaryResponse struct {
+	GameID     string     `json:"game_id"`
+	Player1ID  string     `json:"player1_id,omitempty"`
+	Player2ID  string     `json:"player2_id,omitempty"`
+	WinnerID   string     `json:"winner_id,omitempty"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type GameEventsResponse struct {
+	GameID string              `json:"game_id"`
+	Events []GameEventResponse `json:"events"`
+}
+
+type GameEventResponse struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type UserGamesResponse struct {
+	UserID     string                `json:"user_id"`
+	Games      []GameSummaryResponse `json:"games"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+func (h *HistoryHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/leaderboard", h.handleLeaderboard)
+	mux.HandleFunc("/games/{id}/summary", h.handleSummary)
+	mux.HandleFunc("/games/{id}/events", h.handleEvents)
+	mux.HandleFunc("/users/{id}/games", h.handleUserGames)
+}
+
+func (h *HistoryHandler) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := queryInt(r, "limit", defaultPageLimit)
+	if !ok || limit < 1 || limit > maxPageLimit {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	offset, ok := queryInt(r, "offset", 0)
+	if !ok || offset < 0 {
+		writeError(w, http.StatusBadRequest, "invalid offset")
+		return
+	}
+
+	entries, err := h.Store.GetLeaderboard(r.Context(), limit, offset)
+	if err != nil {
+		h.logf("leaderboard query failed err=%v", err)
+		writeError(w, http.StatusInternalServerError, "failed to load leaderboard")
+		return
+	}
+
+	resp := LeaderboardResponse{Entries: make([]LeaderboardEntryResponse, 0, len(entries)), Limit: limit, Offset: offset}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, LeaderboardEntryResponse{
+			UserID:      entry.UserID,
+			DisplayName: entry.DisplayName,
+			Rating:      int(math.Round(entry.Rating)),
+			Wins:        entry.Wins,
+			Losses:      entry.Losses,
+			TotalGames:  entry.TotalGames,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *HistoryHandler) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	summary, err := h.Store.GetGameSummary(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeLookupError(w, err, "game not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, summaryResponse(summary))
+}
+
+func (h *HistoryHandler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	gameID := r.PathValue("id")
+	if _, err := h.Store.GetGameSummary(r.Context(), gameID); err != nil {
+		h.writeLookupError(w, err, "game not found")
+		return
+	}
+	events, err := h.Store.GetGameEvents(r.Context(), gameID)
+	if err != nil {
+		h.logf("game events query failed game_id=%s err=%v", gameID, err)
+		writeError(w, http.StatusInternalServerError, "failed to load events")
+		return
+	}
+
+	resp := GameEventsResponse{GameID: gameID, Events: make([]GameEventResponse, 0, len(events))}
+	for _, event := range events {
+		resp.Events = append(resp.Events, GameEventResponse{
+			Seq:       event.Seq,
+			Type:      event.EventType,
+			Payload:   event.Payload,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *HistoryHandler) handleUserGames(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := queryInt(r, "limit", defaultPageLimit)
+	if !ok || limit < 1 || limit > maxPageLimit {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	var after *postgres.GameCursor
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		cursor, err := decodeCursor(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		after = &cursor
+	}
+
+	userID := r.PathValue("id")
+	if _, err := h.Store.GetUser(r.Context(), userID); err != nil {
+		h.writeLookupError(w, err, "user not found")
+		return
+	}
+	games, next, err := h.Store.ListUserGames(r.Context(), userID, after, limit)
+	if err != nil {
+		h.logf("user games query failed user_id=%s err=%v", userID, err)
+		writeError(w, http.StatusInternalServerError, "failed to load games")
+		return
+	}
+
+	resp := UserGamesResponse{UserID: userID, Games: make([]GameSummaryResponse, 0, len(games))}
+	for _, summary := range games {
+		resp.Games = append(resp.Games, summaryResponse(summary))
+	}
+	if next != nil {
+		resp.NextCursor = encodeCursor(*next)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *HistoryHandler) writeLookupError(w http.ResponseWriter, err error, notFound string) {
+	if errors.Is(err, postgres.ErrNotFound) {
+		writeError(w, http.StatusNotFound, notFound)
+		return
+	}
+	h.logf("history lookup failed err=%v", err)
+	writeError(w, http.StatusInternalServerError, "lookup failed")
+}
+
+func (h *HistoryHandler) logf(format string, args ...any) {
+	if h.Logger != nil {
+		h.Logger.Printf(format, args...)
+	}
+}
+
+func summaryResponse(summary postgres.GameSummary) GameSummaryResponse {
+	return GameSummaryResponse{
+		GameID:     summary.GameID,
+		Player1ID:  summary.Player1ID,
+		Player2ID:  summary.Player2ID,
+		WinnerID:   summary.WinnerID,
+		Status:     summary.Status,
+		Reason:     summary.Reason,
+		StartedAt:  summary.StartedAt,
+		FinishedAt: summary.FinishedAt,
+	}
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, bool) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	return value, err == nil
+}
+
+// Cursors are opaque to clients: base64 of "<unix nanos>:<game id>".
+func encodeCursor(cursor postgres.GameCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + cursor.GameID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (postgres.GameCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return postgres.GameCursor{}, err
+	}
+	nanos, gameID, ok := strings.Cut(string(raw), ":")
+	if !ok || gameID == "" {
+		return postgres.GameCursor{}, errors.New("malformed cursor")
+	}
+	ns, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return postgres.GameCursor{}, err
+	}
+	return postgres.GameCursor{CreatedAt: time.Unix(0, ns).UTC(), GameID: gameID}, nil
+}
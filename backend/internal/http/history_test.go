@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shipsgame/internal/store/postgres"
+)
+
+type fakeHistoryStore struct {
+	games  map[string]postgres.GameSummary
+	events map[string][]postgres.GameEvent
+	users  map[string]bool
+
+	leaderboardArgs [2]int
+	after           *postgres.GameCursor
+}
+
+func (f *fakeHistoryStore) GetLeaderboard(_ context.Context, limit int, offset int) ([]postgres.LeaderboardEntry, error) {
+	f.leaderboardArgs = [2]int{limit, offset}
+	return []postgres.LeaderboardEntry{{UserID: "u1", DisplayName: "Ada", Rating: 1532.6, Wins: 3, TotalGames: 4, Losses: 1}}, nil
+}
+
+func (f *fakeHistoryStore) GetGameSummary(_ context.Context, gameID string) (postgres.GameSummary, error) {
+	summary, ok := f.games[gameID]
+	if !ok {
+		return postgres.GameSummary{}, postgres.ErrNotFound
+	}
+	return summary, nil
+}
+
+func (f *fakeHistoryStore) GetGameEvents(_ context.Context, gameID string) ([]postgres.GameEvent, error) {
+	return f.events[gameID], nil
+}
+
+func (f *fakeHistoryStore) GetUser(_ context.Context, userID string) (postgres.User, error) {
+	if !f.users[userID] {
+		return postgres.User{}, postgres.ErrNotFound
+	}
+	return postgres.User{ID: userID}, nil
+}
+
+func (f *fakeHistoryStore) ListUserGames(_ context.Context, userID string, after *postgres.GameCursor, limit int) ([]postgres.GameSummary, *postgres.GameCursor, error) {
+	f.after = after
+	game := f.games["game-1"]
+	return []postgres.GameSummary{game}, &postgres.GameCursor{CreatedAt: game.CreatedAt, GameID: game.GameID}, nil
+}
+
+func newTestHistory(t *testing.T) (*fakeHistoryStore, http.Handler) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	store := &fakeHistoryStore{
+		games: map[string]postgres.GameSummary{
+			"game-1": {GameID: "game-1", Player1ID: "u1", WinnerID: "u1", Status: "finished", Reason: "all_sunk", CreatedAt: created},
+		},
+		events: map[string][]postgres.GameEvent{
+			"game-1": {{Seq: 1, EventType: "joined", Payload: json.RawMessage(`{"player":"p1"}`), CreatedAt: created}},
+		},
+		users: map[string]bool{"u1": true},
+	}
+	return store, NewRouter(RouterConfig{HistoryHandler: &HistoryHandler{Store: store}})
+}
+
+func getJSON(t *testing.T, handler http.Handler, path string, wantStatus int, out any) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != wantStatus {
+		t.Fatalf("GET %s: expected %d, got %d: %s", path, wantStatus, rec.Code, rec.Body.String())
+	}
+	if out != nil {
+		if err := json.NewDecoder(rec.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+	}
+}
+
+func TestLeaderboardEndpoint(t *testing.T) {
+	store, handler := newTestHistory(t)
+
+	var resp LeaderboardResponse
+	getJSON(t, handler, "/leaderboard?limit=5&offset=10", http.StatusOK, &resp)
+	if store.leaderboardArgs != [2]int{5, 10} {
+		t.Fatalf("unexpected paging args: %v", store.leaderboardArgs)
+	}
+	if len(resp.Entries) != 1 || resp.Entries[0].DisplayName != "Ada" || resp.Entries[0].Rating != 1533 || resp.Limit != 5 || resp.Offset != 10 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	getJSON(t, handler, "/leaderboard?limit=0", http.StatusBadRequest, nil)
+	getJSON(t, handler, "/leaderboard?offset=-1", http.StatusBadRequest, nil)
+}
+
+func TestGameSummaryAndEventsEndpoints(t *testing.T) {
+	_, handler := newTestHistory(t)
+
+	var summary GameSummaryResponse
+	getJSON(t, handler, "/games/game-1/summary", http.StatusOK, &summary)
+	if summary.WinnerID != "u1" || summary.Reason != "all_sunk" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	var events GameEventsResponse
+	getJSON(t, handler, "/games/game-1/events", http.StatusOK, &events)
+	if len(events.Events) != 1 || events.Events[0].Type != "joined" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	getJSON(t, handler, "/games/missing/summary", http.StatusNotFound, nil)
+	getJSON(t, handler, "/games/missing/events", http.StatusNotFound, nil)
+}
+
+func TestUserGamesEndpointPaginates(t *testing.T) {
+	store, handler := newTestHistory(t)
+
+	var page UserGamesResponse
+	getJSON(t, handler, "/users/u1/games?limit=1", http.StatusOK, &page)
+	if len(page.Games) != 1 || page.NextCursor == "" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	getJSON(t, handler, "/users/u1/games?limit=1&cursor="+page.NextCursor, http.StatusOK, &page)
+	want := store.games["game-1"]
+	if store.after == nil || store.after.GameID != "game-1" || !store.after.CreatedAt.Equal(want.CreatedAt) {
+		t.Fatalf("expected cursor to round-trip, got %+v", store.after)
+	}
+
+	getJSON(t, handler, "/users/u1/games?cursor=bm9wZQ", http.StatusBadRequest, nil)
+	getJSON(t, handler, "/users/nobody/games", http.StatusNotFound, nil)
+}
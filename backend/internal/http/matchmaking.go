@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+
+	"shipsgame/internal/matchmaking"
+)
+
+type MatchmakingHandler struct {
+	Matchmaker *matchmaking.Matchmaker
+	JWTSecret  string
+	Logger     *log.Logger
+}
+
+type EnqueueResponse struct {
+	Status string             `json:"status"`
+	Match  *matchmaking.Match `json:"match,omitempty"`
+}
+
+func (h *MatchmakingHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/matchmaking/enqueue", h.handleEnqueue)
+	mux.HandleFunc("/matchmaking/cancel", h.handleCancel)
+}
+
+func (h *MatchmakingHandler) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := bearerUser(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	match, err := h.Matchmaker.Enqueue(r.Context(), user.UserID)
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Printf("matchmaking enqueue failed user_id=%s err=%v", user.UserID, err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to enqueue")
+		return
+	}
+	if match != nil {
+		writeJSON(w, http.StatusOK, EnqueueResponse{Status: "matched", Match: match})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, EnqueueResponse{Status: "queued"})
+}
+
+func (h *MatchmakingHandler) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user, err := bearerUser(r, h.JWTSecret)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.Matchmaker.Cancel(r.Context(), user.UserID); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to cancel")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
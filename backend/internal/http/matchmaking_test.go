@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/matchmaking"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func TestMatchmakingEnqueue(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	t.Cleanup(func() { _ = store.Close() })
+
+	handler := &MatchmakingHandler{
+		Matchmaker: &matchmaking.Matchmaker{Redis: store, JWTSecret: "secret"},
+		JWTSecret:  "secret",
+	}
+	mux := http.NewServeMux()
+	handler.Register(mux)
+
+	if rec := postJSON(mux, "/matchmaking/enqueue", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rec.Code)
+	}
+
+	rec := postJSON(mux, "/matchmaking/enqueue", "", signTestUserToken(t, "ada"))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := postJSON(mux, "/matchmaking/cancel", "", signTestUserToken(t, "ada")); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if rec := postJSON(mux, "/matchmaking/enqueue", "", signTestUserToken(t, "grace")); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected grace queued after ada left, got %d", rec.Code)
+	}
+
+	rec = postJSON(mux, "/matchmaking/enqueue", "", signTestUserToken(t, "ada"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp EnqueueResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != "matched" || resp.Match == nil || resp.Match.OpponentID != "grace" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
@@ -6,10 +6,13 @@ import (
 )
 
 type RouterConfig struct {
-	WsHandler    http.Handler
-	GamesHandler *GamesHandler
-	CORS         CORSConfig
-	Logger       *log.Logger
+	WsHandler          http.Handler
+	AuthHandler        *AuthHandler
+	GamesHandler       *GamesHandler
+	HistoryHandler     *HistoryHandler
+	MatchmakingHandler *MatchmakingHandler
+	CORS               CORSConfig
+	Logger             *log.Logger
 }
 
 func NewRouter(cfg RouterConfig) http.Handler {
@@ -18,9 +21,18 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	if cfg.WsHandler != nil {
 		mux.Handle("/ws", cfg.WsHandler)
 	}
+	if cfg.AuthHandler != nil {
+		cfg.AuthHandler.Register(mux)
+	}
 	if cfg.GamesHandler != nil {
 		cfg.GamesHandler.Register(mux)
 	}
+	if cfg.HistoryHandler != nil {
+		cfg.HistoryHandler.Register(mux)
+	}
+	if cfg.MatchmakingHandler != nil {
+		cfg.MatchmakingHandler.Register(mux)
+	}
 	handler := CORS(cfg.CORS, mux)
 	if cfg.Logger != nil {
 		handler = RequestLogger(cfg.Logger, handler)
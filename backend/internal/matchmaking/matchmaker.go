@@ -0,0 +1,194 @@
+package matchmaking
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+	"shipsgame/internal/store/postgres"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const (
+	scanBatchSize = 100
+	matchTTL      = 5 * time.Minute
+	gameTokenTTL  = 24 * time.Hour
+)
+
+// DefaultWindow starts at ±50 rating points and widens by 10 per second
+// waited, up to ±400 after 35 seconds. Users are dropped after five minutes.
+var DefaultWindow = redisstore.MatchWindow{Base: 50, Growth: 10, Max: 400, MaxWait: 5 * time.Minute}
+
+type RatingSource interface {
+	GetRating(ctx context.Context, userID string) (float64, error)
+}
+
+// Match is what each side of a pairing receives: their seat in the new game
+// and the game token to connect with.
+type Match struct {
+	GameID     string `json:"game_id"`
+	Player     string `json:"player"`
+	Token      string `json:"token"`
+	OpponentID string `json:"opponent_id"`
+}
+
+// Matchmaker pairs queued users of similar rating into new games. Any number
+// of matchmakers may run against the same Redis; the queue hands each user to
+// at most one of them.
+type Matchmaker struct {
+	Redis     *redisstore.Client
+	Ratings   RatingSource
+	JWTSecret string
+	Window    redisstore.MatchWindow
+	Logger    *log.Logger
+
+	// Notify tells a user about their match. It is also saved in Redis for
+	// a while, for users that connect after the notification went out.
+	Notify func(userID string, match Match)
+	// Expired tells a user that they waited too long and left the queue.
+	Expired func(userID string)
+}
+
+// Enqueue puts userID on the queue and tries to pair them right away. The
+// returned match is nil when they have to wait.
+func (m *Matchmaker) Enqueue(ctx context.Context, userID string) (*Match, error) {
+	rating, err := m.rating(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Redis.JoinQueue(ctx, userID, rating); err != nil {
+		return nil, err
+	}
+	return m.pair(ctx, userID)
+}
+
+func (m *Matchmaker) Cancel(ctx context.Context, userID string) error {
+	return m.Redis.LeaveQueue(ctx, userID)
+}
+
+// Run retries pairing for everyone on the queue every interval, so windows
+// widen for users who are still waiting.
+func (m *Matchmaker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.scan(ctx)
+		}
+	}
+}
+
+func (m *Matchmaker) scan(ctx context.Context) {
+	users, err := m.Redis.QueuedUsers(ctx, scanBatchSize)
+	if err != nil {
+		m.logf("matchmaking scan failed err=%v", err)
+		return
+	}
+	for _, userID := range users {
+		if _, err := m.pair(ctx, userID); err != nil {
+			m.logf("matchmaking pair failed user_id=%s err=%v", userID, err)
+		}
+	}
+}
+
+func (m *Matchmaker) pair(ctx context.Context, userID string) (*Match, error) {
+	window := m.Window
+	if window.Max <= 0 {
+		window = DefaultWindow
+	}
+
+	opponentID, err := m.Redis.PairQueued(ctx, userID, window)
+	if errors.Is(err, redisstore.ErrQueueExpired) {
+		m.logf("matchmaking expired user_id=%s", userID)
+		if m.Expired != nil {
+			m.Expired(userID)
+		}
+		return nil, nil
+	}
+	if err != nil || opponentID == "" {
+		return nil, err
+	}
+
+	matches, err := m.startGame(ctx, userID, opponentID)
+	if err != nil {
+		m.requeue(ctx, userID, opponentID)
+		return nil, err
+	}
+
+	for i, id := range []string{userID, opponentID} {
+		if data, err := json.Marshal(matches[i]); err == nil {
+			if err := m.Redis.SaveMatch(ctx, id, data, matchTTL); err != nil {
+				m.logf("matchmaking save failed user_id=%s err=%v", id, err)
+			}
+		}
+		if m.Notify != nil {
+			m.Notify(id, matches[i])
+		}
+	}
+	m.logf("match found game_id=%s p1=%s p2=%s", matches[0].GameID, userID, opponentID)
+	return &matches[0], nil
+}
+
+// startGame creates the game for a pairing, p1 first, and returns each side's
+// match.
+func (m *Matchmaker) startGame(ctx context.Context, p1, p2 string) ([2]Match, error) {
+	meta, err := m.Redis.CreateGame(ctx, redisstore.GameOptions{P1UserID: p1})
+	if err != nil {
+		return [2]Match{}, err
+	}
+	if _, _, err := m.Redis.JoinGame(ctx, meta.JoinCode, p2); err != nil {
+		return [2]Match{}, err
+	}
+
+	var matches [2]Match
+	for i, seat := range []struct{ player, user, opponent string }{{"p1", p1, p2}, {"p2", p2, p1}} {
+		token, err := auth.SignToken(m.JWTSecret, auth.Claims{
+			GameID: meta.ID,
+			Player: seat.player,
+			UserID: seat.user,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(gameTokenTTL)),
+			},
+		})
+		if err != nil {
+			return [2]Match{}, err
+		}
+		matches[i] = Match{GameID: meta.ID, Player: seat.player, Token: token, OpponentID: seat.opponent}
+	}
+	return matches, nil
+}
+
+// requeue puts both users of a failed pairing back so the next scan can try
+// again.
+func (m *Matchmaker) requeue(ctx context.Context, userIDs ...string) {
+	for _, userID := range userIDs {
+		rating, err := m.rating(ctx, userID)
+		if err == nil {
+			err = m.Redis.JoinQueue(ctx, userID, rating)
+		}
+		if err != nil {
+			m.logf("matchmaking requeue failed user_id=%s err=%v", userID, err)
+		}
+	}
+}
+
+func (m *Matchmaker) rating(ctx context.Context, userID string) (float64, error) {
+	if m.Ratings == nil {
+		return postgres.DefaultInitialRating, nil
+	}
+	return m.Ratings.GetRating(ctx, userID)
+}
+
+func (m *Matchmaker) logf(format string, args ...any) {
+	if m.Logger != nil {
+		m.Logger.Printf(format, args...)
+	}
+}
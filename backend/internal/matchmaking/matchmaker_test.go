@@ -0,0 +1,96 @@
+package matchmaking
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+type fixedRatings map[string]float64
+
+func (f fixedRatings) GetRating(_ context.Context, userID string) (float64, error) {
+	return f[userID], nil
+}
+
+func newTestMatchmaker(t *testing.T, ratings fixedRatings) (*Matchmaker, map[string]Match) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	t.Cleanup(func() { _ = store.Close() })
+
+	var mu sync.Mutex
+	notified := map[string]Match{}
+	return &Matchmaker{
+		Redis:     store,
+		Ratings:   ratings,
+		JWTSecret: "secret",
+		Window:    redisstore.MatchWindow{Base: 50, Growth: 10, Max: 400},
+		Notify: func(userID string, match Match) {
+			mu.Lock()
+			defer mu.Unlock()
+			notified[userID] = match
+		},
+	}, notified
+}
+
+func TestEnqueuePairsUsersIntoGame(t *testing.T) {
+	matchmaker, notified := newTestMatchmaker(t, fixedRatings{"ada": 1500, "grace": 1520})
+	ctx := context.Background()
+
+	match, err := matchmaker.Enqueue(ctx, "ada")
+	if err != nil || match != nil {
+		t.Fatalf("expected ada to wait, got %+v, %v", match, err)
+	}
+
+	match, err = matchmaker.Enqueue(ctx, "grace")
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if match == nil || match.Player != "p1" || match.OpponentID != "ada" {
+		t.Fatalf("expected grace seated as p1 against ada, got %+v", match)
+	}
+
+	if notified["ada"].GameID != match.GameID || notified["ada"].Player != "p2" {
+		t.Fatalf("expected ada notified as p2, got %+v", notified["ada"])
+	}
+	claims, err := auth.ParseToken(notified["ada"].Token, "secret")
+	if err != nil || claims.GameID != match.GameID || claims.Player != "p2" || claims.UserID != "ada" {
+		t.Fatalf("unexpected token claims %+v, %v", claims, err)
+	}
+
+	meta, err := matchmaker.Redis.GetMeta(ctx, match.GameID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if meta.P1UserID != "grace" || meta.P2UserID != "ada" || !meta.P2Joined {
+		t.Fatalf("expected seats bound to the pair, got %+v", meta)
+	}
+
+	pending, err := matchmaker.Redis.PendingMatch(ctx, "ada")
+	if err != nil || len(pending) == 0 {
+		t.Fatalf("expected pending match saved for ada, got %s, %v", pending, err)
+	}
+}
+
+func TestScanWidensWindowUntilPaired(t *testing.T) {
+	matchmaker, notified := newTestMatchmaker(t, fixedRatings{"ada": 1500, "linus": 1560})
+	matchmaker.Window = redisstore.MatchWindow{Base: 50, Growth: 100, Max: 400}
+	ctx := context.Background()
+
+	for _, user := range []string{"ada", "linus"} {
+		if match, err := matchmaker.Enqueue(ctx, user); err != nil || match != nil {
+			t.Fatalf("expected %s to wait, got %+v, %v", user, match, err)
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	matchmaker.scan(ctx)
+
+	if notified["ada"].GameID == "" || notified["ada"].GameID != notified["linus"].GameID {
+		t.Fatalf("expected both paired into one game, got %+v", notified)
+	}
+}
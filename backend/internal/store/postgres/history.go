@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// GameCursor marks a position in a user's history. Games are listed newest
+// first by the time they were archived, with the ID breaking ties.
+type GameCursor struct {
+	CreatedAt time.Time
+	GameID    string
+}
+
+const gameSummaryColumns = `id, player1_id, player2_id, winner_id, status, finish_reason, started_at, finished_at, created_at`
+
+func (s *Store) GetGameSummary(ctx context.Context, gameID string) (GameSummary, error) {
+	summary, err := scanGameSummary(s.db.QueryRow(ctx, `
+		SELECT `+gameSummaryColumns+`
+		FROM games
+		WHERE id = $1
+	`, gameID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return GameSummary{}, ErrNotFound
+	}
+	return summary, err
+}
+
+func (s *Store) GetUser(ctx context.Context, userID string) (User, error) {
+	if !isUUID(userID) {
+		return User{}, ErrNotFound
+	}
+
+	var user User
+	err := s.db.QueryRow(ctx, `
+		SELECT id, display_name, created_at
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&user.ID, &user.DisplayName, &user.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return User{}, ErrNotFound
+	}
+	return user, err
+}
+
+// ListUserGames returns up to limit games userID played, starting after
+// cursor, and the cursor of the last game returned. The returned cursor is nil
+// once there are no more games.
+func (s *Store) ListUserGames(ctx context.Context, userID string, after *GameCursor, limit int) ([]GameSummary, *GameCursor, error) {
+	if !isUUID(userID) {
+		return []GameSummary{}, nil, nil
+	}
+
+	var afterTime, afterID any
+	if after != nil {
+		afterTime, afterID = after.CreatedAt, after.GameID
+	}
+
+	rows, err := s.db.Query(ctx, `
+		SELECT `+gameSummaryColumns+`
+		FROM games
+		WHERE (player1_id = $1 OR player2_id = $1)
+		  AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4
+	`, userID, afterTime, afterID, limit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	games := []GameSummary{}
+	for rows.Next() {
+		summary, err := scanGameSummary(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		games = append(games, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(games) <= limit {
+		return games, nil, nil
+	}
+	games = games[:limit]
+	last := games[len(games)-1]
+	return games, &GameCursor{CreatedAt: last.CreatedAt, GameID: last.GameID}, nil
+}
+
+func scanGameSummary(row pgx.Row) (GameSummary, error) {
+	var summary GameSummary
+	var player1, player2, winner, reason *string
+	err := row.Scan(
+		&summary.GameID, &player1, &player2, &winner, &summary.Status, &reason,
+		&summary.StartedAt, &summary.FinishedAt, &summary.CreatedAt,
+	)
+	if err != nil {
+		return GameSummary{}, err
+	}
+	summary.Player1ID = deref(player1)
+	summary.Player2ID = deref(player2)
+	summary.WinnerID = deref(winner)
+	summary.Reason = deref(reason)
+	switch summary.WinnerID {
+	case "":
+	case summary.Player1ID:
+		summary.LoserID = summary.Player2ID
+	case summary.Player2ID:
+		summary.LoserID = summary.Player1ID
+	}
+	return summary, nil
+}
+
+// isUUID reports whether value can be compared with a uuid column; anything
+// else would make Postgres reject the whole query.
+func isUUID(value string) bool {
+	var id pgtype.UUID
+	return id.Scan(value) == nil
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
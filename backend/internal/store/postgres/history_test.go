@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v2"
+)
+
+const testUserID = "7b0c3f1e-2d4a-4c56-9e8f-0a1b2c3d4e5f"
+
+var summaryColumns = []string{"id", "player1_id", "player2_id", "winner_id", "status", "finish_reason", "started_at", "finished_at", "created_at"}
+
+func TestGetGameSummary(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	winner := testUserID
+	finished := time.Now()
+	mock.ExpectQuery("SELECT id, player1_id").WithArgs("game-1").WillReturnRows(
+		pgxmock.NewRows(summaryColumns).AddRow("game-1", &winner, nil, &winner, "finished", nil, nil, &finished, finished),
+	)
+	mock.ExpectQuery("SELECT id, player1_id").WithArgs("missing").WillReturnRows(pgxmock.NewRows(summaryColumns))
+
+	summary, err := store.GetGameSummary(context.Background(), "game-1")
+	if err != nil {
+		t.Fatalf("get summary: %v", err)
+	}
+	if summary.Player1ID != testUserID || summary.Player2ID != "" || summary.WinnerID != testUserID || summary.LoserID != "" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	if _, err := store.GetGameSummary(context.Background(), "missing"); err != ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListUserGamesPages(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	user := testUserID
+	now := time.Now()
+	rows := pgxmock.NewRows(summaryColumns)
+	for i, id := range []string{"c", "b", "a"} {
+		rows.AddRow(id, &user, nil, nil, "finished", nil, nil, nil, now.Add(-time.Duration(i)*time.Minute))
+	}
+	mock.ExpectQuery("FROM games").WithArgs(testUserID, nil, nil, 3).WillReturnRows(rows)
+
+	games, next, err := store.ListUserGames(context.Background(), testUserID, nil, 2)
+	if err != nil {
+		t.Fatalf("list games: %v", err)
+	}
+	if len(games) != 2 || games[1].GameID != "b" {
+		t.Fatalf("unexpected page: %+v", games)
+	}
+	if next == nil || next.GameID != "b" || !next.CreatedAt.Equal(games[1].CreatedAt) {
+		t.Fatalf("expected cursor at b, got %+v", next)
+	}
+
+	mock.ExpectQuery("FROM games").WithArgs(testUserID, next.CreatedAt, next.GameID, 3).WillReturnRows(
+		pgxmock.NewRows(summaryColumns).AddRow("a", &user, nil, nil, "finished", nil, nil, nil, now),
+	)
+	games, next, err = store.ListUserGames(context.Background(), testUserID, next, 2)
+	if err != nil {
+		t.Fatalf("list games: %v", err)
+	}
+	if len(games) != 1 || next != nil {
+		t.Fatalf("expected last page, got %+v %+v", games, next)
+	}
+
+	if games, _, err := store.ListUserGames(context.Background(), "not-a-uuid", nil, 2); err != nil || len(games) != 0 {
+		t.Fatalf("expected no games for invalid id, got %+v %v", games, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
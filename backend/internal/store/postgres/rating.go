@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultRatingK       = 32
+	DefaultInitialRating = 1500
+)
+
+// RatingConfig tunes the Elo ratings kept on the leaderboard. Zero values fall
+// back to the defaults.
+type RatingConfig struct {
+	K       float64
+	Initial float64
+
+	// MinGames hides players with fewer games from GetLeaderboard, so a
+	// lucky first win does not top the table.
+	MinGames int
+}
+
+func (c RatingConfig) k() float64 {
+	if c.K <= 0 {
+		return DefaultRatingK
+	}
+	return c.K
+}
+
+func (c RatingConfig) initial() float64 {
+	if c.Initial <= 0 {
+		return DefaultInitialRating
+	}
+	return c.Initial
+}
+
+// GetRating returns userID's current rating, or the initial rating when the
+// user has no archived games yet.
+func (s *Store) GetRating(ctx context.Context, userID string) (float64, error) {
+	if !isUUID(userID) {
+		return s.Ratings.initial(), nil
+	}
+
+	var rating float64
+	err := s.db.QueryRow(ctx, `
+		SELECT rating FROM leaderboard WHERE user_id = $1
+	`, userID).Scan(&rating)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.Ratings.initial(), nil
+	}
+	return rating, err
+}
+
+// Elo returns the ratings of winner and loser after their game.
+func Elo(winner, loser, k float64) (float64, float64) {
+	expected := 1 / (1 + math.Pow(10, (loser-winner)/400))
+	delta := k * (1 - expected)
+	return winner + delta, loser - delta
+}
+
+// recordResult counts the game on both players' leaderboard rows, moves
+// their ratings and logs the change. Rows are touched in user ID order so two
+// archives of the same pair can't deadlock.
+func (s *Store) recordResult(ctx context.Context, tx pgx.Tx, gameID, winnerID, loserID string) error {
+	ratings := make(map[string]float64, 2)
+	first, second := winnerID, loserID
+	if second < first {
+		first, second = second, first
+	}
+	for _, userID := range []string{first, second} {
+		wins, losses := 0, 1
+		if userID == winnerID {
+			wins, losses = 1, 0
+		}
+		rating, err := upsertLeaderboard(ctx, tx, userID, wins, losses, s.Ratings.initial())
+		if err != nil {
+			return err
+		}
+		ratings[userID] = rating
+	}
+
+	winnerAfter, loserAfter := Elo(ratings[winnerID], ratings[loserID], s.Ratings.k())
+	for _, update := range []struct {
+		userID string
+		after  float64
+	}{{winnerID, winnerAfter}, {loserID, loserAfter}} {
+		userID, after := update.userID, update.after
+		if _, err := tx.Exec(ctx, `
+			UPDATE leaderboard SET rating = $2 WHERE user_id = $1
+		`, userID, after); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO rating_history (user_id, game_id, rating_before, rating_after)
+			VALUES ($1, $2, $3, $4)
+		`, userID, gameID, ratings[userID], after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	pgxmock "github.com/pashagolub/pgxmock/v2"
+)
+
+func TestElo(t *testing.T) {
+	winner, loser := Elo(1500, 1500, 32)
+	if winner != 1516 || loser != 1484 {
+		t.Fatalf("expected even game to move 16 points, got %v %v", winner, loser)
+	}
+
+	winner, loser = Elo(1800, 1400, 32)
+	if gain := winner - 1800; gain <= 0 || gain > 4 {
+		t.Fatalf("expected small gain for favourite, got %v", gain)
+	}
+	if math.Abs((winner-1800)+(loser-1400)) > 1e-9 {
+		t.Fatalf("expected ratings to be zero-sum, got %v %v", winner, loser)
+	}
+
+	winner, _ = Elo(1400, 1800, 32)
+	if gain := winner - 1400; gain < 28 {
+		t.Fatalf("expected large gain for upset, got %v", gain)
+	}
+}
+
+func TestGetRating(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	mock.ExpectQuery("SELECT rating FROM leaderboard").WithArgs(testUserID).
+		WillReturnRows(pgxmock.NewRows([]string{"rating"}).AddRow(1612.5))
+	mock.ExpectQuery("SELECT rating FROM leaderboard").WithArgs(testUserID).
+		WillReturnRows(pgxmock.NewRows([]string{"rating"}))
+
+	if rating, err := store.GetRating(context.Background(), testUserID); err != nil || rating != 1612.5 {
+		t.Fatalf("expected stored rating, got %v, %v", rating, err)
+	}
+	if rating, err := store.GetRating(context.Background(), testUserID); err != nil || rating != DefaultInitialRating {
+		t.Fatalf("expected initial rating for new player, got %v, %v", rating, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -12,10 +12,13 @@ import (
 type DB interface {
 	Begin(context.Context) (pgx.Tx, error)
 	Query(context.Context, string, ...any) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...any) pgx.Row
 }
 
 type Store struct {
 	db DB
+
+	Ratings RatingConfig
 }
 
 func NewStore(pool *pgxpool.Pool) *Store {
@@ -35,8 +38,12 @@ type GameSummary struct {
 	WinnerID   string
 	LoserID    string
 	Status     string
+	Reason     string
 	StartedAt  *time.Time
 	FinishedAt *time.Time
+
+	// CreatedAt is when the game was archived. SaveGame ignores it.
+	CreatedAt time.Time
 }
 
 type GameEvent struct {
@@ -47,12 +54,17 @@ type GameEvent struct {
 }
 
 type LeaderboardEntry struct {
-	UserID     string
-	Wins       int
-	Losses     int
-	TotalGames int
+	UserID      string
+	DisplayName string
+	Rating      float64
+	Wins        int
+	Losses      int
+	TotalGames  int
 }
 
+// SaveGame archives a finished game. It is idempotent on GameID: when the
+// game is already stored, for example because another instance archived it
+// first, nothing is written and the leaderboard is left alone.
 func (s *Store) SaveGame(ctx context.Context, summary GameSummary, events []GameEvent) error {
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
@@ -62,58 +74,76 @@ func (s *Store) SaveGame(ctx context.Context, summary GameSummary, events []Game
 		_ = tx.Rollback(ctx)
 	}()
 
-	_, err = tx.Exec(ctx, `
-		INSERT INTO games (id, player1_id, player2_id, winner_id, status, started_at, finished_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, summary.GameID, summary.Player1ID, summary.Player2ID, summary.WinnerID, summary.Status, summary.StartedAt, summary.FinishedAt)
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO games (id, player1_id, player2_id, winner_id, status, finish_reason, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO NOTHING
+	`, summary.GameID, nullable(summary.Player1ID), nullable(summary.Player2ID), nullable(summary.WinnerID), summary.Status, summary.Reason, summary.StartedAt, summary.FinishedAt)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return nil
+	}
 
 	for _, event := range events {
 		_, err = tx.Exec(ctx, `
 			INSERT INTO game_events (game_id, seq, event_type, payload, created_at)
 			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (game_id, seq) DO NOTHING
 		`, summary.GameID, event.Seq, event.EventType, event.Payload, event.CreatedAt)
 		if err != nil {
 			return err
 		}
 	}
 
-	if summary.Status == statusFinished && summary.WinnerID != "" {
-		if err := upsertLeaderboard(tx, ctx, summary.WinnerID, 1, 0); err != nil {
+	// Only games between two users count: a seat without one, like the AI's,
+	// could record one side of the result but not the other.
+	if summary.Status == statusFinished && summary.WinnerID != "" && summary.LoserID != "" {
+		if err := s.recordResult(ctx, tx, summary.GameID, summary.WinnerID, summary.LoserID); err != nil {
 			return err
 		}
-		if summary.LoserID != "" {
-			if err := upsertLeaderboard(tx, ctx, summary.LoserID, 0, 1); err != nil {
-				return err
-			}
-		}
 	}
 
 	return tx.Commit(ctx)
 }
 
-func upsertLeaderboard(tx pgx.Tx, ctx context.Context, userID string, winsDelta, lossesDelta int) error {
-	_, err := tx.Exec(ctx, `
-		INSERT INTO leaderboard (user_id, wins, losses, total_games)
-		VALUES ($1, $2, $3, $4)
+// nullable maps an empty ID to NULL for optional uuid columns.
+func nullable(value string) any {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// upsertLeaderboard adds a result to userID's row, creating it at the initial
+// rating, and returns the rating before this game.
+func upsertLeaderboard(ctx context.Context, tx pgx.Tx, userID string, winsDelta, lossesDelta int, initial float64) (float64, error) {
+	var rating float64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO leaderboard (user_id, wins, losses, total_games, rating)
+		VALUES ($1, $2, $3, $4, $5)
 		ON CONFLICT (user_id) DO UPDATE
 		SET wins = leaderboard.wins + EXCLUDED.wins,
 			losses = leaderboard.losses + EXCLUDED.losses,
 			total_games = leaderboard.total_games + EXCLUDED.total_games,
 			updated_at = now()
-	`, userID, winsDelta, lossesDelta, winsDelta+lossesDelta)
-	return err
+		RETURNING rating
+	`, userID, winsDelta, lossesDelta, winsDelta+lossesDelta, initial).Scan(&rating)
+	return rating, err
 }
 
-func (s *Store) GetLeaderboard(ctx context.Context, limit int) ([]LeaderboardEntry, error) {
+// GetLeaderboard ranks players by rating. Players with fewer than
+// Ratings.MinGames games are left out.
+func (s *Store) GetLeaderboard(ctx context.Context, limit int, offset int) ([]LeaderboardEntry, error) {
 	rows, err := s.db.Query(ctx, `
-		SELECT user_id, wins, losses, total_games
-		FROM leaderboard
-		ORDER BY wins DESC, total_games DESC
-		LIMIT $1
-	`, limit)
+		SELECT l.user_id, u.display_name, l.rating, l.wins, l.losses, l.total_games
+		FROM leaderboard l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.total_games >= $3
+		ORDER BY l.rating DESC, l.total_games DESC, l.user_id
+		LIMIT $1 OFFSET $2
+	`, limit, offset, s.Ratings.MinGames)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +152,7 @@ func (s *Store) GetLeaderboard(ctx context.Context, limit int) ([]LeaderboardEnt
 	entries := []LeaderboardEntry{}
 	for rows.Next() {
 		var entry LeaderboardEntry
-		if err := rows.Scan(&entry.UserID, &entry.Wins, &entry.Losses, &entry.TotalGames); err != nil {
+		if err := rows.Scan(&entry.UserID, &entry.DisplayName, &entry.Rating, &entry.Wins, &entry.Losses, &entry.TotalGames); err != nil {
 			return nil, err
 		}
 		entries = append(entries, entry)
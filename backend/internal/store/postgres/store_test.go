@@ -20,7 +20,7 @@ func TestSaveGameWritesAll(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO games").WithArgs(
-		"game-1", "p1", "p2", "p1", "finished", pgxmock.AnyArg(), pgxmock.AnyArg(),
+		"game-1", "p1", "p2", "p1", "finished", "resigned", pgxmock.AnyArg(), pgxmock.AnyArg(),
 	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 	eventPayload, _ := json.Marshal(map[string]string{"type": "fire"})
@@ -28,13 +28,22 @@ func TestSaveGameWritesAll(t *testing.T) {
 		"game-1", 1, "shot", pgxmock.AnyArg(), pgxmock.AnyArg(),
 	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
-	mock.ExpectExec("INSERT INTO leaderboard").WithArgs(
-		"p1", 1, 0, 1,
-	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("INSERT INTO leaderboard").WithArgs(
+		"p1", 1, 0, 1, float64(DefaultInitialRating),
+	).WillReturnRows(pgxmock.NewRows([]string{"rating"}).AddRow(1500.0))
 
-	mock.ExpectExec("INSERT INTO leaderboard").WithArgs(
-		"p2", 0, 1, 1,
-	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("INSERT INTO leaderboard").WithArgs(
+		"p2", 0, 1, 1, float64(DefaultInitialRating),
+	).WillReturnRows(pgxmock.NewRows([]string{"rating"}).AddRow(1500.0))
+
+	mock.ExpectExec("UPDATE leaderboard SET rating").WithArgs("p1", 1516.0).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("INSERT INTO rating_history").WithArgs("p1", "game-1", 1500.0, 1516.0).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("UPDATE leaderboard SET rating").WithArgs("p2", 1484.0).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("INSERT INTO rating_history").WithArgs("p2", "game-1", 1500.0, 1484.0).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 	mock.ExpectCommit()
 
@@ -48,6 +57,7 @@ func TestSaveGameWritesAll(t *testing.T) {
 		WinnerID:   "p1",
 		LoserID:    "p2",
 		Status:     "finished",
+		Reason:     "resigned",
 		StartedAt:  &started,
 		FinishedAt: &finished,
 	}, []GameEvent{{
@@ -65,6 +75,35 @@ func TestSaveGameWritesAll(t *testing.T) {
 	}
 }
 
+func TestSaveGameSkipsArchivedGame(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO games").WithArgs(
+		"game-1", nil, nil, nil, "finished", "all_sunk", pgxmock.AnyArg(), pgxmock.AnyArg(),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectRollback()
+
+	err = store.SaveGame(context.Background(), GameSummary{
+		GameID: "game-1",
+		Status: "finished",
+		Reason: "all_sunk",
+	}, []GameEvent{{Seq: 1, EventType: "joined", Payload: json.RawMessage(`{}`)}})
+	if err != nil {
+		t.Fatalf("save game: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
 func TestGetLeaderboard(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -74,19 +113,55 @@ func TestGetLeaderboard(t *testing.T) {
 
 	store := &Store{db: mock}
 
-	rows := pgxmock.NewRows([]string{"user_id", "wins", "losses", "total_games"}).
-		AddRow("u1", 2, 1, 3).
-		AddRow("u2", 1, 0, 1)
+	store.Ratings.MinGames = 5
+
+	rows := pgxmock.NewRows([]string{"user_id", "display_name", "rating", "wins", "losses", "total_games"}).
+		AddRow("u1", "Ada", 1620.5, 6, 1, 7).
+		AddRow("u2", "Grace", 1540.0, 4, 2, 6)
 
-	mock.ExpectQuery("SELECT user_id, wins, losses, total_games").WithArgs(10).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT l.user_id, u.display_name, l.rating").WithArgs(10, 20, 5).WillReturnRows(rows)
 
-	entries, err := store.GetLeaderboard(context.Background(), 10)
+	entries, err := store.GetLeaderboard(context.Background(), 10, 20)
 	if err != nil {
 		t.Fatalf("get leaderboard: %v", err)
 	}
 	if len(entries) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(entries))
 	}
+	if entries[0].DisplayName != "Ada" || entries[0].Rating != 1620.5 {
+		t.Fatalf("expected display name, got %+v", entries[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSaveGameLeavesLeaderboardOutOfAIGames(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO games").WithArgs(
+		"game-1", "p1", nil, "p1", "finished", "all_sunk", pgxmock.AnyArg(), pgxmock.AnyArg(),
+	).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	err = store.SaveGame(context.Background(), GameSummary{
+		GameID:    "game-1",
+		Player1ID: "p1",
+		WinnerID:  "p1",
+		Status:    "finished",
+		Reason:    "all_sunk",
+	}, nil)
+	if err != nil {
+		t.Fatalf("save game: %v", err)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("expectations: %v", err)
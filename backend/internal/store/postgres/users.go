@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+type User struct {
+	ID          string
+	DisplayName string
+	CreatedAt   time.Time
+}
+
+// CreateUser stores a guest user. Display names are not unique.
+func (s *Store) CreateUser(ctx context.Context, displayName string) (User, error) {
+	var user User
+	err := s.db.QueryRow(ctx, `
+		INSERT INTO users (display_name)
+		VALUES ($1)
+		RETURNING id, display_name, created_at
+	`, displayName).Scan(&user.ID, &user.DisplayName, &user.CreatedAt)
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
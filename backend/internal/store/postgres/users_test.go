@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pgxmock "github.com/pashagolub/pgxmock/v2"
+)
+
+func TestCreateUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("mock pool: %v", err)
+	}
+	defer mock.Close()
+
+	store := &Store{db: mock}
+
+	created := time.Now()
+	mock.ExpectQuery("INSERT INTO users").WithArgs("Ada").WillReturnRows(
+		pgxmock.NewRows([]string{"id", "display_name", "created_at"}).AddRow("user-1", "Ada", created),
+	)
+
+	user, err := store.CreateUser(context.Background(), "Ada")
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if user.ID != "user-1" || user.DisplayName != "Ada" || !user.CreatedAt.Equal(created) {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ChatKindText  = "chat"
+	ChatKindEmote = "emote"
+)
+
+// ChatEntry is one line of a game's chat history. Text is set for chat
+// messages, Emote for emotes. At is unix ms.
+type ChatEntry struct {
+	Kind   string `json:"kind"`
+	Player string `json:"player"`
+	Text   string `json:"text,omitempty"`
+	Emote  string `json:"emote,omitempty"`
+	At     int64  `json:"at"`
+}
+
+// AppendChat adds entry to gameID's chat and keeps only the newest limit
+// entries. When the game is already expiring the chat expires with it. It
+// returns the seq of the game's last event, for stamping the broadcast.
+func (c *Client) AppendChat(ctx context.Context, gameID string, entry ChatEntry, limit int64) (int64, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	res, err := appendChatScript.Run(ctx, c.client, []string{gameMetaKey(gameID), chatKey(gameID)}, data, limit).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch res := res.(type) {
+	case int64:
+		return res, nil
+	case string:
+		if res == "ERR:game_not_found" {
+			return 0, ErrGameNotFound
+		}
+	}
+	return 0, errUnexpectedResponse
+}
+
+// ChatHistory returns the kept chat of gameID, oldest first.
+func (c *Client) ChatHistory(ctx context.Context, gameID string) ([]ChatEntry, error) {
+	raw, err := c.client.LRange(ctx, chatKey(gameID), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ChatEntry, 0, len(raw))
+	for _, item := range raw {
+		var entry ChatEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func chatKey(id string) string {
+	return fmt.Sprintf("game:%s:chat", id)
+}
+
+var appendChatScript = redis.NewScript(`
+local meta = KEYS[1]
+local chat = KEYS[2]
+
+if redis.call('EXISTS', meta) == 0 then
+  return 'ERR:game_not_found'
+end
+
+redis.call('RPUSH', chat, ARGV[1])
+redis.call('LTRIM', chat, -tonumber(ARGV[2]), -1)
+
+local ttl = redis.call('PTTL', meta)
+if ttl > 0 then
+  redis.call('PEXPIRE', chat, ttl)
+end
+return tonumber(redis.call('HGET', meta, 'event_seq')) or 0
+`)
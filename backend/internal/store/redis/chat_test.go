@@ -0,0 +1,49 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChatHistoryIsBounded(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+
+	for _, text := range []string{"one", "two", "three"} {
+		seq, err := client.AppendChat(ctx, meta.ID, ChatEntry{Kind: ChatKindText, Player: playerOne, Text: text}, 2)
+		if err != nil {
+			t.Fatalf("append chat error: %v", err)
+		}
+		if seq != 1 {
+			t.Fatalf("expected chat stamped with the joined event's seq, got %d", seq)
+		}
+	}
+	if _, err := client.AppendChat(ctx, "missing", ChatEntry{Kind: ChatKindText, Text: "hi"}, 2); err != ErrGameNotFound {
+		t.Fatalf("expected game not found, got %v", err)
+	}
+
+	history, err := client.ChatHistory(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("chat history error: %v", err)
+	}
+	if len(history) != 2 || history[0].Text != "two" || history[1].Text != "three" {
+		t.Fatalf("expected the two newest messages, got %+v", history)
+	}
+
+	if err := client.ExpireGame(ctx, meta.ID, time.Minute); err != nil {
+		t.Fatalf("expire game error: %v", err)
+	}
+	if _, err := client.AppendChat(ctx, meta.ID, ChatEntry{Kind: ChatKindEmote, Player: playerTwo, Emote: "gg"}, 2); err != nil {
+		t.Fatalf("append chat error: %v", err)
+	}
+	if ttl := client.client.TTL(ctx, chatKey(meta.ID)).Val(); ttl <= 0 {
+		t.Fatalf("expected chat to expire with the game, got ttl %s", ttl)
+	}
+}
@@ -2,11 +2,40 @@ package redisstore
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+var errUnexpectedResponse = errors.New("unexpected redis response")
+
+// IsUnavailable reports whether err came from talking to Redis rather than
+// from the game refusing a move: the connection, a timeout, or an error reply
+// from the server. Such a call may succeed when tried again.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return false
+	}
+	var netErr net.Error
+	var redisErr redis.Error
+	switch {
+	case errors.Is(err, errUnexpectedResponse),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &netErr),
+		errors.As(err, &redisErr):
+		return true
+	}
+	// The client's own errors, such as a closed client or an exhausted pool.
+	return strings.HasPrefix(err.Error(), "redis: ")
+}
+
 type Client struct {
 	client *redis.Client
 }
@@ -0,0 +1,36 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/game"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := NewClient(Config{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	_, err = client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 0, Col: 0})
+	if err == nil || IsUnavailable(err) {
+		t.Fatalf("expected a rule error, got %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 99, Col: 0}); IsUnavailable(err) {
+		t.Fatalf("expected out of bounds to be a rule error, got %v", err)
+	}
+
+	server.Close()
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 0, Col: 0}); !IsUnavailable(err) {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+	if !IsUnavailable(errUnexpectedResponse) || IsUnavailable(nil) {
+		t.Fatalf("unexpected classification")
+	}
+}
@@ -0,0 +1,59 @@
+package redisstore
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidTimeControl = errors.New("invalid time control")
+	ErrClockExpired       = errors.New("clock expired")
+)
+
+// Clock is the chess-clock style time bank of a game. P1 and P2 hold the bank
+// as of the start of the current turn; the running player's bank keeps
+// draining from TurnStarted until they move.
+type Clock struct {
+	TimeBank    time.Duration
+	Increment   time.Duration
+	P1          time.Duration
+	P2          time.Duration
+	Running     string
+	TurnStarted time.Time
+}
+
+func (c Clock) Enabled() bool {
+	return c.TimeBank > 0
+}
+
+func (c Clock) Remaining(player string, now time.Time) time.Duration {
+	remaining := c.P1
+	if player == playerTwo {
+		remaining = c.P2
+	}
+	if player == c.Running && !c.TurnStarted.IsZero() {
+		remaining -= now.Sub(c.TurnStarted)
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func parseClock(fields map[string]string) Clock {
+	clock := Clock{
+		TimeBank:    msToDuration(fields["time_bank_ms"]),
+		Increment:   msToDuration(fields["increment_ms"]),
+		P1:          msToDuration(fields["p1_clock_ms"]),
+		P2:          msToDuration(fields["p2_clock_ms"]),
+		TurnStarted: msToTime(fields["turn_started"]),
+	}
+	if clock.Enabled() && fields["status"] == "active" {
+		clock.Running = fields["turn"]
+	}
+	return clock
+}
+
+func msToDuration(value string) time.Duration {
+	return time.Duration(atoi(value)) * time.Millisecond
+}
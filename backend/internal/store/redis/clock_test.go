@@ -0,0 +1,156 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"shipsgame/internal/game"
+)
+
+func startClockGame(t *testing.T, client *Client, bank, increment time.Duration) GameMeta {
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{TimeBank: bank, Increment: increment})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+	return meta
+}
+
+func rewindTurnStart(t *testing.T, client *Client, gameID string, by time.Duration) {
+	started := time.Now().Add(-by).UnixMilli()
+	if err := client.client.HSet(context.Background(), gameMetaKey(gameID), "turn_started", started).Err(); err != nil {
+		t.Fatalf("set turn start: %v", err)
+	}
+}
+
+func TestCreateGameRejectsInvalidTimeControl(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	for _, opts := range []GameOptions{
+		{TimeBank: -time.Second},
+		{TimeBank: time.Minute, Increment: -time.Second},
+		{Increment: time.Second},
+	} {
+		if _, err := client.CreateGame(context.Background(), opts); err != ErrInvalidTimeControl {
+			t.Fatalf("expected invalid time control for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestClockChargedOnFire(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startClockGame(t, client, time.Minute, 2*time.Second)
+
+	started, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if started.Clock.Running != playerOne || started.Clock.TurnStarted.IsZero() {
+		t.Fatalf("expected p1 clock running, got %+v", started.Clock)
+	}
+
+	rewindTurnStart(t, client, meta.ID, 10*time.Second)
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 9, Col: 9}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+
+	after, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if after.Clock.P1 < 51*time.Second || after.Clock.P1 > 52*time.Second {
+		t.Fatalf("expected about 52s left for p1, got %s", after.Clock.P1)
+	}
+	if after.Clock.P2 != time.Minute || after.Clock.Running != playerTwo {
+		t.Fatalf("expected p2 clock running with full bank, got %+v", after.Clock)
+	}
+
+	ids, err := client.ExpiredTurns(ctx, time.Now().Add(2*time.Minute), 10)
+	if err != nil {
+		t.Fatalf("expired turns error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != meta.ID {
+		t.Fatalf("expected game indexed by its flag time, got %v", ids)
+	}
+}
+
+func TestFireWithEmptyClockLoses(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startClockGame(t, client, time.Minute, 0)
+
+	rewindTurnStart(t, client, meta.ID, 2*time.Minute)
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 5, Col: 5}); err != ErrClockExpired {
+		t.Fatalf("expected clock expired, got %v", err)
+	}
+
+	finished, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if finished.Status != "finished" || finished.Winner != playerTwo || finished.FinishReason != FinishTimeout {
+		t.Fatalf("expected p2 to win on time, got %+v", finished)
+	}
+	if finished.P2Remaining != 2 || finished.Clock.P1 != 0 {
+		t.Fatalf("expected shot not applied and p1 clock empty, got %+v", finished)
+	}
+}
+
+func TestExpireTurnFlagsEmptyClock(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startClockGame(t, client, time.Minute, 0)
+
+	if _, err := client.ExpireTurn(ctx, meta.ID); err != ErrTurnNotExpired {
+		t.Fatalf("expected not expired, got %v", err)
+	}
+
+	rewindTurnStart(t, client, meta.ID, 2*time.Minute)
+	if err := client.client.ZAdd(ctx, turnDeadlinesKey, redis.Z{Score: 1, Member: meta.ID}).Err(); err != nil {
+		t.Fatalf("set deadline index: %v", err)
+	}
+
+	expiry, err := client.ExpireTurn(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("expire turn error: %v", err)
+	}
+	if !expiry.Flagged || expiry.Player != playerOne {
+		t.Fatalf("expected p1 flagged, got %+v", expiry)
+	}
+
+	finished, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if finished.Winner != playerTwo || finished.FinishReason != FinishTimeout {
+		t.Fatalf("expected p2 to win on time, got %+v", finished)
+	}
+
+	ids, err := client.ExpiredTurns(ctx, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("expired turns error: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("expected clock removed from index, got %v", ids)
+	}
+}
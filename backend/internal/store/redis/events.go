@@ -0,0 +1,104 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	EventJoined      = "joined"
+	EventPlaced      = "placed"
+	EventShot        = "shot"
+	EventSunk        = "sunk"
+	EventTurnSkipped = "turn_skipped"
+	EventFinished    = "finished"
+)
+
+// GameEvent is one entry of a game's event log. Seq starts at 1 and has no
+// gaps; At is the Redis time of the event in unix ms. Ship positions are never
+// logged, so the log can be shown to either player.
+type GameEvent struct {
+	Seq     int64  `json:"seq"`
+	Type    string `json:"type"`
+	Player  string `json:"player,omitempty"`
+	Coord   string `json:"coord,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+	Ship    string `json:"ship,omitempty"`
+	Winner  string `json:"winner,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	At      int64  `json:"at"`
+}
+
+// GetEvents returns the events of gameID with a sequence number greater than
+// sinceSeq, oldest first.
+func (c *Client) GetEvents(ctx context.Context, gameID string, sinceSeq int64) ([]GameEvent, error) {
+	if sinceSeq < 0 {
+		sinceSeq = 0
+	}
+
+	raw, err := c.client.LRange(ctx, eventsKey(gameID), sinceSeq, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		exists, err := c.client.Exists(ctx, gameMetaKey(gameID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			return nil, ErrGameNotFound
+		}
+	}
+
+	events := make([]GameEvent, 0, len(raw))
+	for _, entry := range raw {
+		var event GameEvent
+		if err := json.Unmarshal([]byte(entry), &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// EventSeq returns the seq of the last event logged for gameID, 0 before the
+// first one.
+func (c *Client) EventSeq(ctx context.Context, gameID string) (int64, error) {
+	seq, err := c.client.HGet(ctx, gameMetaKey(gameID), "event_seq").Int64()
+	if errors.Is(err, redis.Nil) {
+		exists, err := c.client.Exists(ctx, gameMetaKey(gameID)).Result()
+		if err != nil {
+			return 0, err
+		}
+		if exists == 0 {
+			return 0, ErrGameNotFound
+		}
+		return 0, nil
+	}
+	return seq, err
+}
+
+func eventsKey(id string) string {
+	return fmt.Sprintf("game:%s:events", id)
+}
+
+// luaEvents appends to the event log from inside the script that changes the
+// game, so the log and the game state can never disagree. The list index of
+// an event is its seq - 1.
+const luaEvents = `
+local function now_ms()
+  local t = redis.call('TIME')
+  return tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+end
+
+local function append_event(meta, events, event)
+  event['seq'] = redis.call('HINCRBY', meta, 'event_seq', 1)
+  event['at'] = now_ms()
+  redis.call('RPUSH', events, cjson.encode(event))
+  return event['seq']
+end
+`
@@ -0,0 +1,133 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+
+	"shipsgame/internal/game"
+)
+
+func TestEventLogRecordsGame(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, ""); err != nil {
+		t.Fatalf("join game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+	for _, shot := range []struct {
+		player string
+		coord  game.Coord
+		seq    int64
+	}{
+		{playerOne, game.Coord{Row: 5, Col: 5}, 5},
+		{playerTwo, game.Coord{Row: 9, Col: 9}, 6},
+		{playerOne, game.Coord{Row: 5, Col: 6}, 8},
+	} {
+		result, err := client.Fire(ctx, meta.ID, shot.player, shot.coord)
+		if err != nil {
+			t.Fatalf("fire error: %v", err)
+		}
+		if result.Seq != shot.seq {
+			t.Fatalf("expected shot at %+v logged as seq %d, got %d", shot.coord, shot.seq, result.Seq)
+		}
+	}
+	finished, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if finished.EventSeq != 9 {
+		t.Fatalf("expected meta read at seq 9, got %d", finished.EventSeq)
+	}
+
+	events, err := client.GetEvents(ctx, meta.ID, 0)
+	if err != nil {
+		t.Fatalf("get events error: %v", err)
+	}
+
+	want := []GameEvent{
+		{Type: EventJoined, Player: playerOne},
+		{Type: EventJoined, Player: playerTwo},
+		{Type: EventPlaced, Player: playerOne},
+		{Type: EventPlaced, Player: playerTwo},
+		{Type: EventShot, Player: playerOne, Coord: "5,5", Outcome: "hit"},
+		{Type: EventShot, Player: playerTwo, Coord: "9,9", Outcome: "miss"},
+		{Type: EventShot, Player: playerOne, Coord: "5,6", Outcome: "hit"},
+		{Type: EventSunk, Player: playerOne, Coord: "5,6", Ship: "destroyer"},
+		{Type: EventFinished, Winner: playerOne, Reason: FinishAllSunk},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, event := range events {
+		if event.Seq != int64(i+1) || event.At == 0 {
+			t.Fatalf("event %d has seq %d at %d", i, event.Seq, event.At)
+		}
+		event.Seq, event.At = 0, 0
+		if event != want[i] {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want[i], event)
+		}
+	}
+
+	tail, err := client.GetEvents(ctx, meta.ID, 7)
+	if err != nil {
+		t.Fatalf("get events error: %v", err)
+	}
+	if len(tail) != 2 || tail[0].Seq != 8 || tail[1].Type != EventFinished {
+		t.Fatalf("expected events after seq 7, got %+v", tail)
+	}
+
+	none, err := client.GetEvents(ctx, meta.ID, 9)
+	if err != nil || len(none) != 0 {
+		t.Fatalf("expected no newer events, got %+v err=%v", none, err)
+	}
+	if seq, err := client.EventSeq(ctx, meta.ID); err != nil || seq != 9 {
+		t.Fatalf("expected event seq 9, got %d err=%v", seq, err)
+	}
+}
+
+func TestEventLogSeatedAndResigned(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{Opponent: OpponentAI})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if _, err := client.Resign(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+
+	events, err := client.GetEvents(ctx, meta.ID, 0)
+	if err != nil {
+		t.Fatalf("get events error: %v", err)
+	}
+	if len(events) != 3 || events[1].Type != EventJoined || events[1].Player != playerTwo {
+		t.Fatalf("expected both seats joined, got %+v", events)
+	}
+	if events[2].Seq != 3 || events[2].Type != EventFinished || events[2].Reason != FinishResigned || events[2].Winner != playerTwo {
+		t.Fatalf("expected the seated AI to win by resignation, got %+v", events[2])
+	}
+
+	if _, err := client.GetEvents(ctx, "missing", 0); err != ErrGameNotFound {
+		t.Fatalf("expected game not found, got %v", err)
+	}
+	if _, err := client.EventSeq(ctx, "missing"); err != ErrGameNotFound {
+		t.Fatalf("expected game not found, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Finish reasons stored in the meta hash once a game is over.
+const (
+	FinishAllSunk   = "all_sunk"
+	FinishResigned  = "resigned"
+	FinishTimeout   = "timeout"
+	FinishAbandoned = "abandoned"
+)
+
+var ErrGameFinished = errors.New("game already finished")
+
+// Resign ends gameID on behalf of player. Once p2 has joined the opponent
+// wins, during placement too; a game left while still waiting for p2 is
+// abandoned without a winner, and its join code is dropped.
+func (c *Client) Resign(ctx context.Context, gameID string, player string) (GameMeta, error) {
+	if player != playerOne && player != playerTwo {
+		return GameMeta{}, ErrInvalidPlayer
+	}
+
+	joinCode, err := c.client.HGet(ctx, gameMetaKey(gameID), "join_code").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return GameMeta{}, err
+	}
+
+	res, err := resignScript.Run(ctx, c.client, []string{
+		gameMetaKey(gameID),
+		turnDeadlinesKey,
+		eventsKey(gameID),
+		archiveOutboxKey,
+		lobbyKey,
+		joinCodeKey(joinCode),
+	}, player).Result()
+	if err != nil {
+		return GameMeta{}, err
+	}
+
+	resultStr, ok := res.(string)
+	if !ok {
+		return GameMeta{}, errUnexpectedResponse
+	}
+	switch resultStr {
+	case "OK":
+	case "ERR:game_not_found":
+		return GameMeta{}, ErrGameNotFound
+	case "ERR:game_finished":
+		return GameMeta{}, ErrGameFinished
+	default:
+		return GameMeta{}, errors.New(strings.TrimPrefix(resultStr, "ERR:"))
+	}
+
+	return c.GetMeta(ctx, gameID)
+}
+
+// ExpireGame sets a TTL on every key of gameID once it no longer needs to
+// live in Redis, typically after it was archived.
+func (c *Client) ExpireGame(ctx context.Context, gameID string, ttl time.Duration) error {
+	meta, err := c.GetMeta(ctx, gameID)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{gameMetaKey(gameID), eventsKey(gameID), gameStreamKey(gameID), chatKey(gameID)}
+	for _, player := range []string{playerOne, playerTwo} {
+		keys = append(keys,
+			boardKey(gameID, player),
+			occupancyKey(gameID, player),
+			shipsKey(gameID, player),
+			shotsKey(gameID, player),
+		)
+	}
+	if meta.JoinCode != "" {
+		keys = append(keys, joinCodeKey(meta.JoinCode))
+	}
+
+	pipe := c.client.TxPipeline()
+	for _, key := range keys {
+		pipe.Expire(ctx, key, ttl)
+	}
+	pipe.ZRem(ctx, lobbyKey, gameID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+var resignScript = redis.NewScript(luaRules + luaEvents + luaTurns + `
+local meta = KEYS[1]
+local deadlines = KEYS[2]
+local events = KEYS[3]
+local outbox = KEYS[4]
+local lobby = KEYS[5]
+local join_code = KEYS[6]
+
+local player = ARGV[1]
+
+if redis.call('EXISTS', meta) == 0 then
+  return 'ERR:game_not_found'
+end
+
+local status = redis.call('HGET', meta, 'status')
+if status == 'finished' then
+  return 'ERR:game_finished'
+end
+
+if status == 'active' or redis.call('HGET', meta, 'p2_joined') == '1' then
+  finish_game(meta, events, (player == 'p1') and 'p2' or 'p1', 'resigned', deadlines, outbox)
+else
+  finish_game(meta, events, '', 'abandoned', deadlines, outbox)
+  redis.call('ZREM', lobby, redis.call('HGET', meta, 'id'))
+  redis.call('DEL', join_code)
+end
+return 'OK'
+`)
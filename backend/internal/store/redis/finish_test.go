@@ -0,0 +1,159 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shipsgame/internal/game"
+)
+
+func TestResignActiveGame(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+
+	finished, err := client.Resign(ctx, meta.ID, playerOne)
+	if err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+	if finished.Status != "finished" || finished.Winner != playerTwo || finished.FinishReason != FinishResigned {
+		t.Fatalf("expected p2 to win by resignation, got %+v", finished)
+	}
+	if !finished.TurnDeadline.IsZero() {
+		t.Fatalf("expected turn clock stopped, got %s", finished.TurnDeadline)
+	}
+
+	ids, err := client.ExpiredTurns(ctx, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("expired turns error: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("expected turn clock removed from index, got %v", ids)
+	}
+
+	if _, err := client.Resign(ctx, meta.ID, playerTwo); err != ErrGameFinished {
+		t.Fatalf("expected game finished, got %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, playerTwo, game.Coord{Row: 0, Col: 0}); err == nil {
+		t.Fatalf("expected fire after resign to fail")
+	}
+}
+
+func TestResignBeforeStartAbandons(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+
+	finished, err := client.Resign(ctx, meta.ID, playerOne)
+	if err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+	if finished.Status != "finished" || finished.Winner != "" || finished.FinishReason != FinishAbandoned {
+		t.Fatalf("expected abandoned game without winner, got %+v", finished)
+	}
+
+	if _, err := client.Resign(ctx, "missing", playerOne); err != ErrGameNotFound {
+		t.Fatalf("expected game not found, got %v", err)
+	}
+}
+
+func TestAbandonedGameCannotBeJoined(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{P1UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if _, err := client.Resign(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, "user-2"); err != ErrInvalidJoinCode {
+		t.Fatalf("expected the join code dropped, got %v", err)
+	}
+
+	// A join that still resolves the code, e.g. one racing the resign, is
+	// refused by the script.
+	if err := client.client.Set(ctx, joinCodeKey(meta.JoinCode), meta.ID, 0).Err(); err != nil {
+		t.Fatalf("restore join code: %v", err)
+	}
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, "user-2"); err != ErrGameNotJoinable {
+		t.Fatalf("expected game not joinable, got %v", err)
+	}
+
+	stored, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.P2Joined || stored.P2UserID != "" {
+		t.Fatalf("expected no p2 on the abandoned game, got %+v", stored)
+	}
+	events, err := client.GetEvents(ctx, meta.ID, 0)
+	if err != nil {
+		t.Fatalf("get events error: %v", err)
+	}
+	if last := events[len(events)-1]; last.Type != EventFinished {
+		t.Fatalf("expected the log to end with finished, got %+v", last)
+	}
+}
+
+func TestResignDuringPlacementAwardsOpponent(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, ""); err != nil {
+		t.Fatalf("join game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships error: %v", err)
+	}
+
+	finished, err := client.Resign(ctx, meta.ID, playerTwo)
+	if err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+	if finished.Status != "finished" || finished.Winner != playerOne || finished.FinishReason != FinishResigned {
+		t.Fatalf("expected p1 to win by resignation, got %+v", finished)
+	}
+}
+
+func TestExpireGameSetsTTL(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+	if _, err := client.Resign(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+
+	if err := client.ExpireGame(ctx, meta.ID, time.Hour); err != nil {
+		t.Fatalf("expire game error: %v", err)
+	}
+	for _, key := range []string{gameMetaKey(meta.ID), eventsKey(meta.ID), boardKey(meta.ID, playerTwo), joinCodeKey(meta.JoinCode)} {
+		ttl, err := client.client.TTL(ctx, key).Result()
+		if err != nil {
+			t.Fatalf("ttl error: %v", err)
+		}
+		if ttl <= 0 || ttl > time.Hour {
+			t.Fatalf("expected ttl on %s, got %s", key, ttl)
+		}
+	}
+}
@@ -7,22 +7,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"shipsgame/internal/game"
 )
 
 var (
-	ErrGameNotFound     = errors.New("game not found")
-	ErrGameFull         = errors.New("game already has two players")
-	ErrInvalidJoinCode  = errors.New("invalid join code")
-	ErrNotPlayerTurn    = errors.New("not player's turn")
-	ErrGameNotActive    = errors.New("game not active")
-	ErrPlayerNotReady   = errors.New("player not ready")
-	ErrOpponentNotReady = errors.New("opponent not ready")
-	ErrInvalidPlayer    = errors.New("invalid player")
-	ErrInvalidPlacement = errors.New("invalid ship placement")
+	ErrGameNotFound       = errors.New("game not found")
+	ErrGameFull           = errors.New("game already has two players")
+	ErrInvalidJoinCode    = errors.New("invalid join code")
+	ErrNotPlayerTurn      = errors.New("not player's turn")
+	ErrGameNotActive      = errors.New("game not active")
+	ErrPlayerNotReady     = errors.New("player not ready")
+	ErrOpponentNotReady   = errors.New("opponent not ready")
+	ErrInvalidPlayer      = errors.New("invalid player")
+	ErrInvalidPlacement   = errors.New("invalid ship placement")
+	ErrOwnGame            = errors.New("cannot join own game")
+	ErrGameNotJoinable    = errors.New("game can no longer be joined")
+	ErrInvalidVisibility  = errors.New("invalid visibility")
+	ErrInvalidRevealDelay = errors.New("invalid reveal delay")
 )
 
 const (
@@ -30,6 +36,11 @@ const (
 	playerTwo = "p2"
 )
 
+const (
+	OpponentHuman = "human"
+	OpponentAI    = "ai"
+)
+
 type GameMeta struct {
 	ID          string
 	JoinCode    string
@@ -42,58 +53,241 @@ type GameMeta struct {
 	P2Joined    bool
 	P1Remaining int
 	P2Remaining int
+	Rules       game.Ruleset
+	Opponent    string
+	Difficulty  string
+
+	TurnTimeout   time.Duration
+	TimeoutAction string
+	TurnDeadline  time.Time
+	Clock         Clock
+	FinishReason  string
+
+	StartingPlayer     string
+	RematchRequestedBy string
+	RematchGameID      string
+
+	P1UserID string
+	P2UserID string
+
+	Visibility string
+	P1Name     string
+
+	RevealDelay time.Duration
+
+	// EventSeq is the seq of the last event logged when the meta was read.
+	EventSeq int64
+}
+
+type GameOptions struct {
+	Ruleset       game.Ruleset
+	Opponent      string
+	Difficulty    string
+	TurnTimeout   time.Duration
+	TimeoutAction string
+	TimeBank      time.Duration
+	Increment     time.Duration
+
+	StartingPlayer string
+
+	// P1UserID and P2UserID bind the seats to users. P2UserID is only used
+	// for seated games; otherwise it is set by JoinGame.
+	P1UserID string
+	P2UserID string
+
+	// Visibility public lists a game in the lobby until p2 joins. P1Name is
+	// shown there as the host.
+	Visibility string
+	P1Name     string
+
+	// RevealDelay, when set, lets spectators see both fleets as the game
+	// stood this long ago. Without it spectators only see shots and sunk
+	// ships.
+	RevealDelay time.Duration
 }
 
 type ShotResult struct {
 	Outcome  game.ShotOutcome
 	ShipType game.ShipType
+
+	// Seq is the seq of the event that logged the shot; for a shot that sank
+	// a ship, the sunk event.
+	Seq int64
 }
 
 type ShipsPlacement map[game.ShipType][]game.Coord
 
-func (c *Client) CreateGame(ctx context.Context) (GameMeta, error) {
+func (c *Client) CreateGame(ctx context.Context, opts GameOptions) (GameMeta, error) {
 	id, err := randomHex(12)
 	if err != nil {
 		return GameMeta{}, err
 	}
-	joinCode, err := randomHex(3)
+	return c.createGame(ctx, id, opts, opts.Opponent == OpponentAI)
+}
+
+// createGame stores a new game under id. When seated is true both seats are
+// taken from the start and no join code is issued.
+func (c *Client) createGame(ctx context.Context, id string, opts GameOptions, seated bool) (GameMeta, error) {
+	rules := opts.Ruleset
+	if rules.Name == "" {
+		rules = game.ClassicRuleset()
+	}
+	if err := rules.Validate(); err != nil {
+		return GameMeta{}, err
+	}
+	rulesJSON, err := json.Marshal(rules)
 	if err != nil {
 		return GameMeta{}, err
 	}
+	opponentType := opts.Opponent
+	if opponentType == "" {
+		opponentType = OpponentHuman
+	}
+	timeoutAction := opts.TimeoutAction
+	if timeoutAction == "" {
+		timeoutAction = TimeoutSkip
+	}
+	if opts.TurnTimeout < 0 || (timeoutAction != TimeoutSkip && timeoutAction != TimeoutRandomShot) {
+		return GameMeta{}, ErrInvalidTurnTimeout
+	}
+	if opts.TimeBank < 0 || opts.Increment < 0 || (opts.Increment > 0 && opts.TimeBank == 0) {
+		return GameMeta{}, ErrInvalidTimeControl
+	}
+	startingPlayer := opts.StartingPlayer
+	if startingPlayer == "" {
+		startingPlayer = playerOne
+	}
+	if startingPlayer != playerOne && startingPlayer != playerTwo {
+		return GameMeta{}, ErrInvalidPlayer
+	}
+	visibility := opts.Visibility
+	if visibility == "" {
+		visibility = VisibilityPrivate
+	}
+	if visibility != VisibilityPrivate && visibility != VisibilityPublic {
+		return GameMeta{}, ErrInvalidVisibility
+	}
+	if opts.RevealDelay < 0 {
+		return GameMeta{}, ErrInvalidRevealDelay
+	}
+
+	p2Joined := 0
+	joinCode := ""
+	if seated {
+		p2Joined = 1
+	} else {
+		joinCode, err = randomHex(3)
+		if err != nil {
+			return GameMeta{}, err
+		}
+	}
+
+	joined := []any{}
+	for _, player := range []string{playerOne, playerTwo} {
+		if player == playerTwo && !seated {
+			break
+		}
+		event, err := json.Marshal(GameEvent{
+			Seq:    int64(len(joined) + 1),
+			Type:   EventJoined,
+			Player: player,
+			At:     time.Now().UnixMilli(),
+		})
+		if err != nil {
+			return GameMeta{}, err
+		}
+		joined = append(joined, string(event))
+	}
 
 	metaKey := gameMetaKey(id)
-	joinKey := joinCodeKey(joinCode)
 	pipe := c.client.TxPipeline()
 
 	pipe.HSet(ctx, metaKey, map[string]any{
 		"id":           id,
 		"join_code":    joinCode,
 		"status":       "waiting",
-		"turn":         playerOne,
+		"turn":         startingPlayer,
 		"winner":       "",
 		"p1_ready":     0,
 		"p2_ready":     0,
 		"p1_joined":    1,
-		"p2_joined":    0,
+		"p2_joined":    p2Joined,
 		"p1_remaining": 0,
 		"p2_remaining": 0,
+		"ruleset":      rules.Name,
+		"rules":        string(rulesJSON),
+		"opponent":     opponentType,
+		"difficulty":   opts.Difficulty,
+
+		"turn_timeout_ms": opts.TurnTimeout.Milliseconds(),
+		"timeout_action":  timeoutAction,
+		"turn_deadline":   0,
+
+		"time_bank_ms":  opts.TimeBank.Milliseconds(),
+		"increment_ms":  opts.Increment.Milliseconds(),
+		"p1_clock_ms":   opts.TimeBank.Milliseconds(),
+		"p2_clock_ms":   opts.TimeBank.Milliseconds(),
+		"turn_started":  0,
+		"finish_reason": "",
+
+		"starting_player": startingPlayer,
+		"event_seq":       len(joined),
+
+		"p1_user": opts.P1UserID,
+		"p2_user": opts.P2UserID,
+
+		"visibility": visibility,
+		"p1_name":    opts.P1Name,
+
+		"reveal_delay_ms": opts.RevealDelay.Milliseconds(),
 	})
-	pipe.Set(ctx, joinKey, id, 0)
+	pipe.RPush(ctx, eventsKey(id), joined...)
+	if joinCode != "" {
+		pipe.Set(ctx, joinCodeKey(joinCode), id, 0)
+		if visibility == VisibilityPublic {
+			pipe.ZAdd(ctx, lobbyKey, redis.Z{Score: float64(time.Now().UnixMilli()), Member: id})
+		}
+	}
 
 	if _, err := pipe.Exec(ctx); err != nil {
 		return GameMeta{}, err
 	}
 
 	return GameMeta{
-		ID:       id,
-		JoinCode: joinCode,
-		Status:   "waiting",
-		Turn:     playerOne,
-		Winner:   "",
+		ID:         id,
+		JoinCode:   joinCode,
+		Status:     "waiting",
+		Turn:       startingPlayer,
+		Winner:     "",
+		P1Joined:   true,
+		P2Joined:   p2Joined == 1,
+		Rules:      rules,
+		Opponent:   opponentType,
+		Difficulty: opts.Difficulty,
+
+		TurnTimeout:   opts.TurnTimeout,
+		TimeoutAction: timeoutAction,
+		Clock: Clock{
+			TimeBank:  opts.TimeBank,
+			Increment: opts.Increment,
+			P1:        opts.TimeBank,
+			P2:        opts.TimeBank,
+		},
+		StartingPlayer: startingPlayer,
+
+		P1UserID: opts.P1UserID,
+		P2UserID: opts.P2UserID,
+
+		Visibility: visibility,
+		P1Name:     opts.P1Name,
+
+		RevealDelay: opts.RevealDelay,
 	}, nil
 }
 
-func (c *Client) JoinGame(ctx context.Context, joinCode string) (GameMeta, string, error) {
+// JoinGame seats userID as p2 of the game behind joinCode. The creator of a
+// game can't join it a second time.
+func (c *Client) JoinGame(ctx context.Context, joinCode string, userID string) (GameMeta, string, error) {
 	id, err := c.client.Get(ctx, joinCodeKey(joinCode)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -103,10 +297,18 @@ func (c *Client) JoinGame(ctx context.Context, joinCode string) (GameMeta, strin
 	}
 
 	metaKey := gameMetaKey(id)
-	res, err := joinGameScript.Run(ctx, c.client, []string{metaKey}).Result()
+	res, err := joinGameScript.Run(ctx, c.client, []string{metaKey, eventsKey(id), lobbyKey}, userID).Result()
 	if err != nil {
 		return GameMeta{}, "", err
 	}
+	switch res {
+	case "ERR:game_full":
+		return GameMeta{}, "", ErrGameFull
+	case "ERR:own_game":
+		return GameMeta{}, "", ErrOwnGame
+	case "ERR:game_not_joinable":
+		return GameMeta{}, "", ErrGameNotJoinable
+	}
 	if resStr, ok := res.(string); ok && resStr != "OK" {
 		if strings.HasPrefix(resStr, "ERR:") {
 			return GameMeta{}, "", errors.New(strings.TrimPrefix(resStr, "ERR:"))
@@ -131,9 +333,14 @@ func (c *Client) PlaceShips(ctx context.Context, gameID string, player string, p
 		return ErrInvalidPlayer
 	}
 
-	board := game.NewBoard()
+	meta, err := c.GetMeta(ctx, gameID)
+	if err != nil {
+		return err
+	}
+
+	board := game.NewBoardWithRules(meta.Rules)
 	for shipType, coords := range placement {
-		orientation, start, err := validateCoords(shipType, coords)
+		orientation, start, err := validateCoords(meta.Rules, shipType, coords)
 		if err != nil {
 			return err
 		}
@@ -166,6 +373,8 @@ func (c *Client) PlaceShips(ctx context.Context, gameID string, player string, p
 		boardKey(gameID, player),
 		occupancyKey(gameID, player),
 		shipsKey(gameID, player),
+		turnDeadlinesKey,
+		eventsKey(gameID),
 	}
 
 	args := []any{
@@ -185,6 +394,9 @@ func (c *Client) PlaceShips(ctx context.Context, gameID string, player string, p
 	if err != nil {
 		return err
 	}
+	if res == "ERR:out_of_bounds" {
+		return game.ErrOutOfBounds
+	}
 	if resStr, ok := res.(string); ok && resStr != "OK" {
 		return errors.New(resStr)
 	}
@@ -195,17 +407,16 @@ func (c *Client) Fire(ctx context.Context, gameID string, player string, coord g
 	if player != playerOne && player != playerTwo {
 		return ShotResult{}, ErrInvalidPlayer
 	}
-	if !coord.InBounds() {
-		return ShotResult{}, game.ErrOutOfBounds
-	}
 
 	args := []any{player, coordKey(coord)}
 	res, err := fireScript.Run(ctx, c.client, []string{
 		gameMetaKey(gameID),
 		shotsKey(gameID, player),
-		shotsKey(gameID, opponent(player)),
 		occupancyKey(gameID, opponent(player)),
 		shipsKey(gameID, opponent(player)),
+		turnDeadlinesKey,
+		eventsKey(gameID),
+		archiveOutboxKey,
 	}, args...).Result()
 	if err != nil {
 		return ShotResult{}, err
@@ -213,16 +424,87 @@ func (c *Client) Fire(ctx context.Context, gameID string, player string, coord g
 
 	resultStr, ok := res.(string)
 	if !ok {
-		return ShotResult{}, errors.New("unexpected redis response")
+		return ShotResult{}, errUnexpectedResponse
+	}
+	switch resultStr {
+	case "ERR:out_of_bounds":
+		return ShotResult{}, game.ErrOutOfBounds
+	case "ERR:clock_expired":
+		return ShotResult{}, ErrClockExpired
 	}
 	if strings.HasPrefix(resultStr, "ERR:") {
 		return ShotResult{}, errors.New(strings.TrimPrefix(resultStr, "ERR:"))
 	}
 
-	parts := strings.Split(resultStr, ":")
-	outcome := parts[0]
-	shot := ShotResult{}
-	switch outcome {
+	return parseShot(resultStr)
+}
+
+func (c *Client) FireSalvo(ctx context.Context, gameID string, player string, coords []game.Coord) ([]ShotResult, error) {
+	if player != playerOne && player != playerTwo {
+		return nil, ErrInvalidPlayer
+	}
+	if len(coords) == 0 {
+		return nil, game.ErrInvalidSalvo
+	}
+
+	args := []any{player}
+	for _, coord := range coords {
+		args = append(args, coordKey(coord))
+	}
+	res, err := fireSalvoScript.Run(ctx, c.client, []string{
+		gameMetaKey(gameID),
+		shotsKey(gameID, player),
+		shipsKey(gameID, player),
+		occupancyKey(gameID, opponent(player)),
+		shipsKey(gameID, opponent(player)),
+		turnDeadlinesKey,
+		eventsKey(gameID),
+		archiveOutboxKey,
+	}, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	resultStr, ok := res.(string)
+	if !ok {
+		return nil, errUnexpectedResponse
+	}
+	switch resultStr {
+	case "ERR:out_of_bounds":
+		return nil, game.ErrOutOfBounds
+	case "ERR:already_shot":
+		return nil, game.ErrAlreadyShot
+	case "ERR:invalid_salvo":
+		return nil, game.ErrInvalidSalvo
+	case "ERR:clock_expired":
+		return nil, ErrClockExpired
+	}
+	if strings.HasPrefix(resultStr, "ERR:") {
+		return nil, errors.New(strings.TrimPrefix(resultStr, "ERR:"))
+	}
+
+	outcomes := strings.Split(resultStr, ";")
+	results := make([]ShotResult, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		shot, err := parseShot(outcome)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, shot)
+	}
+	return results, nil
+}
+
+// parseShot parses an "outcome|seq" result returned by the shot scripts.
+func parseShot(value string) (ShotResult, error) {
+	value, seqStr, _ := strings.Cut(value, "|")
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil {
+		return ShotResult{}, errUnexpectedResponse
+	}
+	parts := strings.Split(value, ":")
+	shot := ShotResult{Seq: seq}
+	switch parts[0] {
 	case "miss":
 		shot.Outcome = game.ShotMiss
 	case "hit":
@@ -239,8 +521,8 @@ func (c *Client) Fire(ctx context.Context, gameID string, player string, coord g
 	return shot, nil
 }
 
-func validateCoords(shipType game.ShipType, coords []game.Coord) (game.Orientation, game.Coord, error) {
-	size, ok := game.StandardShipSet[shipType]
+func validateCoords(rules game.Ruleset, shipType game.ShipType, coords []game.Coord) (game.Orientation, game.Coord, error) {
+	size, ok := rules.ShipSize(shipType)
 	if !ok {
 		return 0, game.Coord{}, game.ErrUnknownShipType
 	}
@@ -257,7 +539,7 @@ func validateCoords(shipType game.ShipType, coords []game.Coord) (game.Orientati
 	sameCol := true
 
 	for _, coord := range coords {
-		if !coord.InBounds() {
+		if !rules.InBounds(coord) {
 			return 0, game.Coord{}, game.ErrOutOfBounds
 		}
 		if seen[coord] {
@@ -348,6 +630,29 @@ func joinCodeKey(code string) string {
 }
 
 func parseMeta(fields map[string]string) GameMeta {
+	opponentType := fields["opponent"]
+	if opponentType == "" {
+		opponentType = OpponentHuman
+	}
+
+	startingPlayer := fields["starting_player"]
+	if startingPlayer == "" {
+		startingPlayer = playerOne
+	}
+
+	visibility := fields["visibility"]
+	if visibility == "" {
+		visibility = VisibilityPrivate
+	}
+
+	rules := game.ClassicRuleset()
+	if raw := fields["rules"]; raw != "" {
+		var stored game.Ruleset
+		if err := json.Unmarshal([]byte(raw), &stored); err == nil {
+			rules = stored
+		}
+	}
+
 	return GameMeta{
 		ID:          fields["id"],
 		JoinCode:    fields["join_code"],
@@ -360,6 +665,29 @@ func parseMeta(fields map[string]string) GameMeta {
 		P2Joined:    fields["p2_joined"] == "1",
 		P1Remaining: atoi(fields["p1_remaining"]),
 		P2Remaining: atoi(fields["p2_remaining"]),
+		Rules:       rules,
+		Opponent:    opponentType,
+		Difficulty:  fields["difficulty"],
+
+		TurnTimeout:   time.Duration(atoi(fields["turn_timeout_ms"])) * time.Millisecond,
+		TimeoutAction: fields["timeout_action"],
+		TurnDeadline:  msToTime(fields["turn_deadline"]),
+		Clock:         parseClock(fields),
+		FinishReason:  fields["finish_reason"],
+
+		StartingPlayer:     startingPlayer,
+		RematchRequestedBy: fields["rematch_requested_by"],
+		RematchGameID:      fields["rematch_game"],
+
+		P1UserID: fields["p1_user"],
+		P2UserID: fields["p2_user"],
+
+		Visibility: visibility,
+		P1Name:     fields["p1_name"],
+
+		RevealDelay: time.Duration(atoi(fields["reveal_delay_ms"])) * time.Millisecond,
+
+		EventSeq: int64(atoi(fields["event_seq"])),
 	}
 }
 
@@ -392,11 +720,166 @@ func shipsToPayload(placement ShipsPlacement) map[string][][]int {
 	return result
 }
 
-var placeShipsScript = redis.NewScript(`
+// luaRules is prepended to scripts that need the game's ruleset. Games created
+// before rulesets existed have no rules field and play classic 10x10.
+const luaRules = `
+local function load_rules(meta)
+  local raw = redis.call('HGET', meta, 'rules')
+  if not raw then
+    return {width = 10, height = 10, shots_per_turn = 1}
+  end
+  return cjson.decode(raw)
+end
+
+local function in_bounds(coord, rules)
+  local row, col = string.match(coord, '^(-?%d+),(-?%d+)$')
+  row = tonumber(row)
+  col = tonumber(col)
+  if not row or not col then
+    return false
+  end
+  return row >= 0 and row < tonumber(rules['height']) and col >= 0 and col < tonumber(rules['width'])
+end
+
+local function is_salvo(rules)
+  return rules['salvo'] ~= nil and rules['salvo'] ~= ''
+end
+`
+
+// luaTurns holds the shot bookkeeping shared by every script that fires, the
+// turn clock and the time banks. It needs luaEvents. Deadlines use the Redis
+// clock so every backend instance agrees on when a turn expires. The
+// deadlines ZSET scores a game by whichever comes first: the move deadline or
+// the running player's bank running out.
+const luaTurns = `
+local function clock_enabled(meta)
+  return (tonumber(redis.call('HGET', meta, 'time_bank_ms')) or 0) > 0
+end
+
+local function clock_remaining(meta, player, now)
+  local started = tonumber(redis.call('HGET', meta, 'turn_started')) or now
+  return tonumber(redis.call('HGET', meta, player .. '_clock_ms')) - (now - started)
+end
+
+-- charge_clock takes the time spent on this turn from the player's bank and
+-- credits the increment per shot. It returns false once the bank is empty.
+local function charge_clock(meta, player, now, shots)
+  if not clock_enabled(meta) then
+    return true
+  end
+  local remaining = clock_remaining(meta, player, now)
+  if remaining <= 0 then
+    redis.call('HSET', meta, player .. '_clock_ms', 0)
+    return false
+  end
+  local increment = tonumber(redis.call('HGET', meta, 'increment_ms')) or 0
+  redis.call('HSET', meta, player .. '_clock_ms', remaining + increment * shots)
+  return true
+end
+
+-- bot_retry_ms is how long the AI has to move before sweepers retry its turn.
+-- The AI always sits in p2 (bot.Seat).
+local bot_retry_ms = 5000
+
+local function bot_turn(meta)
+  return redis.call('HGET', meta, 'opponent') == 'ai' and redis.call('HGET', meta, 'turn') == 'p2'
+end
+
+-- schedule_turn indexes the game by the earliest of the turn deadline, the
+-- running player's flag time and, on the AI's turn, the bot retry.
+local function schedule_turn(meta, deadlines, now)
+  local wake = tonumber(redis.call('HGET', meta, 'turn_deadline')) or 0
+  if clock_enabled(meta) then
+    local flag = now + clock_remaining(meta, redis.call('HGET', meta, 'turn'), now)
+    if wake == 0 or flag < wake then
+      wake = flag
+    end
+  end
+  if bot_turn(meta) then
+    local retry = now + bot_retry_ms
+    if wake == 0 or retry < wake then
+      wake = retry
+    end
+  end
+  if wake > 0 then
+    redis.call('ZADD', deadlines, wake, redis.call('HGET', meta, 'id'))
+  else
+    redis.call('ZREM', deadlines, redis.call('HGET', meta, 'id'))
+  end
+end
+
+local function start_turn_clock(meta, deadlines)
+  local now = now_ms()
+  local timeout = tonumber(redis.call('HGET', meta, 'turn_timeout_ms')) or 0
+  if timeout > 0 then
+    redis.call('HSET', meta, 'turn_deadline', now + timeout)
+  end
+  if clock_enabled(meta) then
+    redis.call('HSET', meta, 'turn_started', now)
+  end
+  schedule_turn(meta, deadlines, now)
+end
+
+local function stop_turn_clock(meta, deadlines)
+  redis.call('HSET', meta, 'turn_deadline', 0)
+  redis.call('HSET', meta, 'turn_started', 0)
+  redis.call('ZREM', deadlines, redis.call('HGET', meta, 'id'))
+end
+
+-- finish_game also queues the game on the archive outbox, so a finished game
+-- is archived even if the instance that finished it goes away.
+local function finish_game(meta, events, winner, reason, deadlines, outbox)
+  redis.call('HSET', meta, 'status', 'finished')
+  redis.call('HSET', meta, 'winner', winner)
+  redis.call('HSET', meta, 'finish_reason', reason)
+  stop_turn_clock(meta, deadlines)
+  local seq = append_event(meta, events, {type = 'finished', winner = winner, reason = reason})
+  redis.call('LPUSH', outbox, redis.call('HGET', meta, 'id'))
+  return seq
+end
+
+-- apply_shot returns the outcome and the seq of the last event it logged.
+local function apply_shot(meta, events, player, coord, shooter_shots, opponent_occupancy, opponent_ships)
+  local outcome = 'miss'
+  local ship_type = redis.call('HGET', opponent_occupancy, coord)
+  local sunk = false
+  if ship_type then
+    outcome = 'hit'
+    local remaining = tonumber(redis.call('HINCRBY', opponent_ships, ship_type, -1))
+    local remaining_total_field = (player == 'p1') and 'p2_remaining' or 'p1_remaining'
+    redis.call('HINCRBY', meta, remaining_total_field, -1)
+    if remaining == 0 then
+      outcome = 'sunk:' .. ship_type
+      sunk = true
+    end
+  end
+  redis.call('HSET', shooter_shots, coord, outcome)
+  local seq = append_event(meta, events, {type = 'shot', player = player, coord = coord, outcome = sunk and 'hit' or outcome})
+  if sunk then
+    seq = append_event(meta, events, {type = 'sunk', player = player, coord = coord, ship = ship_type})
+  end
+  return outcome, seq
+end
+
+local function end_turn(meta, events, player, deadlines, outbox)
+  local remaining_total_field = (player == 'p1') and 'p2_remaining' or 'p1_remaining'
+  if tonumber(redis.call('HGET', meta, remaining_total_field)) <= 0 then
+    finish_game(meta, events, player, 'all_sunk', deadlines, outbox)
+    return
+  end
+  local next = (player == 'p1') and 'p2' or 'p1'
+  redis.call('HSET', meta, 'turn', next)
+  start_turn_clock(meta, deadlines)
+end
+`
+
+var placeShipsScript = redis.NewScript(luaRules + luaEvents + luaTurns + `
 local meta = KEYS[1]
 local board = KEYS[2]
 local occupancy = KEYS[3]
 local ships = KEYS[4]
+local deadlines = KEYS[5]
+local events = KEYS[6]
 
 local player = ARGV[1]
 local ships_json = ARGV[2]
@@ -417,13 +900,22 @@ if already_ready == '1' then
   return 'ERR:already_ready'
 end
 
+local rules = load_rules(meta)
+local idx = 4
+while idx <= #ARGV and ARGV[idx] ~= '__ships__' do
+  if not in_bounds(ARGV[idx], rules) then
+    return 'ERR:out_of_bounds'
+  end
+  idx = idx + 2
+end
+
 redis.call('DEL', board)
 redis.call('DEL', occupancy)
 redis.call('DEL', ships)
 
 redis.call('HSET', board, 'ships', ships_json)
 
-local idx = 4
+idx = 4
 while idx <= #ARGV and ARGV[idx] ~= '__ships__' do
   redis.call('HSET', occupancy, ARGV[idx], ARGV[idx + 1])
   idx = idx + 2
@@ -437,11 +929,13 @@ end
 
 redis.call('HSET', meta, ready_field, 1)
 redis.call('HSET', meta, player .. '_remaining', remaining_total)
+append_event(meta, events, {type = 'placed', player = player})
 
 local p1_ready = redis.call('HGET', meta, 'p1_ready')
 local p2_ready = redis.call('HGET', meta, 'p2_ready')
 if p1_ready == '1' and p2_ready == '1' then
   redis.call('HSET', meta, 'status', 'active')
+  start_turn_clock(meta, deadlines)
 else
   redis.call('HSET', meta, 'status', 'placing')
 end
@@ -449,12 +943,14 @@ end
 return 'OK'
 `)
 
-var fireScript = redis.NewScript(`
+var fireScript = redis.NewScript(luaRules + luaEvents + luaTurns + `
 local meta = KEYS[1]
 local shooter_shots = KEYS[2]
-local opponent_shots = KEYS[3]
-local opponent_occupancy = KEYS[4]
-local opponent_ships = KEYS[5]
+local opponent_occupancy = KEYS[3]
+local opponent_ships = KEYS[4]
+local deadlines = KEYS[5]
+local events = KEYS[6]
+local outbox = KEYS[7]
 
 local player = ARGV[1]
 local coord = ARGV[2]
@@ -463,6 +959,14 @@ if redis.call('EXISTS', meta) == 0 then
   return 'ERR:game_not_found'
 end
 
+local rules = load_rules(meta)
+if is_salvo(rules) then
+  return 'ERR:salvo_required'
+end
+if not in_bounds(coord, rules) then
+  return 'ERR:out_of_bounds'
+end
+
 local status = redis.call('HGET', meta, 'status')
 if status ~= 'active' then
   return 'ERR:game_not_active'
@@ -478,38 +982,94 @@ if already then
   return 'ERR:already_shot'
 end
 
-local ship_type = redis.call('HGET', opponent_occupancy, coord)
-if not ship_type then
-  redis.call('HSET', shooter_shots, coord, 'miss')
-  local next = (player == 'p1') and 'p2' or 'p1'
-  redis.call('HSET', meta, 'turn', next)
-  return 'miss'
+if not charge_clock(meta, player, now_ms(), 1) then
+  finish_game(meta, events, (player == 'p1') and 'p2' or 'p1', 'timeout', deadlines, outbox)
+  return 'ERR:clock_expired'
 end
 
-redis.call('HSET', shooter_shots, coord, 'hit')
-local next = (player == 'p1') and 'p2' or 'p1'
+local outcome, seq = apply_shot(meta, events, player, coord, shooter_shots, opponent_occupancy, opponent_ships)
+end_turn(meta, events, player, deadlines, outbox)
+return outcome .. '|' .. seq
+`)
+
+var fireSalvoScript = redis.NewScript(luaRules + luaEvents + luaTurns + `
+local meta = KEYS[1]
+local shooter_shots = KEYS[2]
+local shooter_ships = KEYS[3]
+local opponent_occupancy = KEYS[4]
+local opponent_ships = KEYS[5]
+local deadlines = KEYS[6]
+local events = KEYS[7]
+local outbox = KEYS[8]
+
+local player = ARGV[1]
+local count = #ARGV - 1
 
-local remaining = tonumber(redis.call('HINCRBY', opponent_ships, ship_type, -1))
-local remaining_total_field = (player == 'p1') and 'p2_remaining' or 'p1_remaining'
-local remaining_total = tonumber(redis.call('HINCRBY', meta, remaining_total_field, -1))
+if redis.call('EXISTS', meta) == 0 then
+  return 'ERR:game_not_found'
+end
 
-if remaining == 0 then
-  redis.call('HSET', shooter_shots, coord, 'sunk:' .. ship_type)
-  if remaining_total == 0 then
-    redis.call('HSET', meta, 'status', 'finished')
-    redis.call('HSET', meta, 'winner', player)
-  else
-    redis.call('HSET', meta, 'turn', next)
+local status = redis.call('HGET', meta, 'status')
+if status ~= 'active' then
+  return 'ERR:game_not_active'
+end
+
+local turn = redis.call('HGET', meta, 'turn')
+if turn ~= player then
+  return 'ERR:not_player_turn'
+end
+
+local rules = load_rules(meta)
+if not is_salvo(rules) then
+  return 'ERR:salvo_not_allowed'
+end
+
+local allowed = tonumber(rules['shots_per_turn'])
+if rules['salvo'] == 'survivors' then
+  allowed = 0
+  for _, remaining in ipairs(redis.call('HVALS', shooter_ships)) do
+    if tonumber(remaining) > 0 then
+      allowed = allowed + 1
+    end
+  end
+end
+if count < 1 or count > allowed then
+  return 'ERR:invalid_salvo'
+end
+
+local seen = {}
+for i = 2, #ARGV do
+  local coord = ARGV[i]
+  if not in_bounds(coord, rules) then
+    return 'ERR:out_of_bounds'
   end
-  return 'sunk:' .. ship_type
+  if seen[coord] or redis.call('HEXISTS', shooter_shots, coord) == 1 then
+    return 'ERR:already_shot'
+  end
+  seen[coord] = true
+end
+
+if not charge_clock(meta, player, now_ms(), count) then
+  finish_game(meta, events, (player == 'p1') and 'p2' or 'p1', 'timeout', deadlines, outbox)
+  return 'ERR:clock_expired'
 end
 
-redis.call('HSET', meta, 'turn', next)
-return 'hit'
+local outcomes = {}
+for i = 2, #ARGV do
+  local outcome, seq = apply_shot(meta, events, player, ARGV[i], shooter_shots, opponent_occupancy, opponent_ships)
+  table.insert(outcomes, outcome .. '|' .. seq)
+end
+end_turn(meta, events, player, deadlines, outbox)
+
+return table.concat(outcomes, ';')
 `)
 
-var joinGameScript = redis.NewScript(`
+var joinGameScript = redis.NewScript(luaEvents + `
 local meta = KEYS[1]
+local events = KEYS[2]
+local lobby = KEYS[3]
+
+local user_id = ARGV[1]
 
 if redis.call('EXISTS', meta) == 0 then
   return 'ERR:game_not_found'
@@ -520,6 +1080,18 @@ if p2_joined == '1' then
   return 'ERR:game_full'
 end
 
+local status = redis.call('HGET', meta, 'status')
+if status ~= 'waiting' and status ~= 'placing' then
+  return 'ERR:game_not_joinable'
+end
+
+if user_id ~= '' and redis.call('HGET', meta, 'p1_user') == user_id then
+  return 'ERR:own_game'
+end
+
 redis.call('HSET', meta, 'p2_joined', 1)
+redis.call('HSET', meta, 'p2_user', user_id)
+redis.call('ZREM', lobby, redis.call('HGET', meta, 'id'))
+append_event(meta, events, {type = 'joined', player = 'p2'})
 return 'OK'
 `)
@@ -23,7 +23,7 @@ func TestCreateAndJoinGame(t *testing.T) {
 	defer cleanup()
 
 	ctx := context.Background()
-	meta, err := client.CreateGame(ctx)
+	meta, err := client.CreateGame(ctx, GameOptions{P1UserID: "user-1"})
 	if err != nil {
 		t.Fatalf("create game error: %v", err)
 	}
@@ -31,7 +31,11 @@ func TestCreateAndJoinGame(t *testing.T) {
 		t.Fatalf("expected id and join code")
 	}
 
-	joined, player, err := client.JoinGame(ctx, meta.JoinCode)
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, "user-1"); err != ErrOwnGame {
+		t.Fatalf("expected own game error, got %v", err)
+	}
+
+	joined, player, err := client.JoinGame(ctx, meta.JoinCode, "user-2")
 	if err != nil {
 		t.Fatalf("join game error: %v", err)
 	}
@@ -41,6 +45,13 @@ func TestCreateAndJoinGame(t *testing.T) {
 	if player != playerTwo {
 		t.Fatalf("expected player two, got %s", player)
 	}
+	if joined.P1UserID != "user-1" || joined.P2UserID != "user-2" {
+		t.Fatalf("expected seats bound to users, got %q and %q", joined.P1UserID, joined.P2UserID)
+	}
+
+	if _, _, err := client.JoinGame(ctx, meta.JoinCode, "user-3"); err != ErrGameFull {
+		t.Fatalf("expected game full, got %v", err)
+	}
 }
 
 func TestPlaceShipsAndActivate(t *testing.T) {
@@ -48,7 +59,7 @@ func TestPlaceShipsAndActivate(t *testing.T) {
 	defer cleanup()
 
 	ctx := context.Background()
-	meta, err := client.CreateGame(ctx)
+	meta, err := client.CreateGame(ctx, GameOptions{})
 	if err != nil {
 		t.Fatalf("create game error: %v", err)
 	}
@@ -83,7 +94,7 @@ func TestFireFlow(t *testing.T) {
 	defer cleanup()
 
 	ctx := context.Background()
-	meta, err := client.CreateGame(ctx)
+	meta, err := client.CreateGame(ctx, GameOptions{})
 	if err != nil {
 		t.Fatalf("create game error: %v", err)
 	}
@@ -126,4 +137,166 @@ func TestFireFlow(t *testing.T) {
 	if result.Outcome != game.ShotSunk {
 		t.Fatalf("expected sunk, got %v", result.Outcome)
 	}
+
+	finished, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if finished.Status != "finished" || finished.Winner != playerOne || finished.FinishReason != FinishAllSunk {
+		t.Fatalf("expected p1 to win by sinking, got %+v", finished)
+	}
+}
+
+func TestRulesetStoredAndHonored(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{Ruleset: game.SmallRuleset()})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+
+	stored, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.Rules.Name != game.RulesetSmall || stored.Rules.Width != 8 {
+		t.Fatalf("expected small ruleset, got %+v", stored.Rules)
+	}
+
+	outside := ShipsPlacement{
+		game.Destroyer: {{Row: 8, Col: 0}, {Row: 8, Col: 1}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, outside); err != game.ErrOutOfBounds {
+		t.Fatalf("expected out of bounds error, got %v", err)
+	}
+
+	p1Placement := ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		"destroyer_2":  {{Row: 2, Col: 0}, {Row: 2, Col: 1}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, p1Placement); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	p2Placement := ShipsPlacement{
+		game.Destroyer: {{Row: 7, Col: 6}, {Row: 7, Col: 7}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, p2Placement); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 9, Col: 9}); err != game.ErrOutOfBounds {
+		t.Fatalf("expected out of bounds error, got %v", err)
+	}
+	result, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 7, Col: 7})
+	if err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+	if result.Outcome != game.ShotHit {
+		t.Fatalf("expected hit, got %v", result.Outcome)
+	}
+}
+
+func TestFireSalvoFlow(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{Ruleset: game.SalvoRuleset()})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+
+	p1Placement := ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		game.Submarine: {{Row: 2, Col: 0}, {Row: 3, Col: 0}, {Row: 4, Col: 0}},
+	}
+	p2Placement := ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, p1Placement); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, p2Placement); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 5, Col: 5}); err == nil {
+		t.Fatalf("expected single fire to be rejected in salvo game")
+	}
+
+	tooMany := []game.Coord{{Row: 1, Col: 1}, {Row: 1, Col: 2}, {Row: 1, Col: 3}}
+	if _, err := client.FireSalvo(ctx, meta.ID, playerOne, tooMany); err != game.ErrInvalidSalvo {
+		t.Fatalf("expected invalid salvo error, got %v", err)
+	}
+
+	results, err := client.FireSalvo(ctx, meta.ID, playerOne, []game.Coord{{Row: 5, Col: 5}, {Row: 9, Col: 9}})
+	if err != nil {
+		t.Fatalf("fire salvo error: %v", err)
+	}
+	if len(results) != 2 || results[0].Outcome != game.ShotHit || results[1].Outcome != game.ShotMiss {
+		t.Fatalf("unexpected salvo results: %+v", results)
+	}
+
+	stored, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.Turn != playerTwo {
+		t.Fatalf("expected turn to pass to p2, got %s", stored.Turn)
+	}
+
+	if _, err := client.FireSalvo(ctx, meta.ID, playerTwo, []game.Coord{{Row: 0, Col: 0}, {Row: 0, Col: 1}}); err != game.ErrInvalidSalvo {
+		t.Fatalf("expected p2 limited to one shot, got %v", err)
+	}
+	if _, err := client.FireSalvo(ctx, meta.ID, playerTwo, []game.Coord{{Row: 9, Col: 0}}); err != nil {
+		t.Fatalf("fire salvo p2 error: %v", err)
+	}
+
+	results, err = client.FireSalvo(ctx, meta.ID, playerOne, []game.Coord{{Row: 5, Col: 6}})
+	if err != nil {
+		t.Fatalf("fire salvo error: %v", err)
+	}
+	if results[0].Outcome != game.ShotSunk || results[0].ShipType != game.Destroyer {
+		t.Fatalf("expected sunk destroyer, got %+v", results[0])
+	}
+
+	stored, err = client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.Status != "finished" || stored.Winner != playerOne {
+		t.Fatalf("expected p1 to win, got status=%s winner=%s", stored.Status, stored.Winner)
+	}
+}
+
+func TestPlaceShipsRejectsAdjacent(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	rules := game.ClassicRuleset()
+	rules.Adjacency = game.AdjacencyNoTouch
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{Ruleset: rules})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+
+	touching := ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		game.Submarine: {{Row: 1, Col: 2}, {Row: 2, Col: 2}, {Row: 3, Col: 2}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, touching); err != game.ErrAdjacent {
+		t.Fatalf("expected adjacent error, got %v", err)
+	}
+
+	apart := ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		game.Submarine: {{Row: 2, Col: 2}, {Row: 3, Col: 2}, {Row: 4, Col: 2}},
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, apart); err != nil {
+		t.Fatalf("place ships error: %v", err)
+	}
 }
@@ -0,0 +1,83 @@
+package redisstore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const lobbyKey = "games:lobby"
+
+const (
+	VisibilityPrivate = "private"
+	VisibilityPublic  = "public"
+)
+
+// LobbyEntry is a public game still waiting for p2.
+type LobbyEntry struct {
+	GameID    string
+	JoinCode  string
+	Ruleset   string
+	HostName  string
+	CreatedAt time.Time
+}
+
+// ListLobby returns up to limit public games waiting for an opponent, newest
+// first. Entries older than maxAge, or whose game is gone or already started,
+// are dropped from the index on the way; zero maxAge keeps them.
+func (c *Client) ListLobby(ctx context.Context, limit int64, maxAge time.Duration) ([]LobbyEntry, error) {
+	if maxAge > 0 {
+		cutoff := time.Now().Add(-maxAge).UnixMilli()
+		if err := c.client.ZRemRangeByScore(ctx, lobbyKey, "-inf", "("+strconv.FormatInt(cutoff, 10)).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := c.client.ZRevRangeWithScores(ctx, lobbyKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	pipe := c.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(members))
+	for i, member := range members {
+		cmds[i] = pipe.HMGet(ctx, gameMetaKey(member.Member.(string)), "join_code", "ruleset", "p1_name", "status", "p2_joined")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	entries := make([]LobbyEntry, 0, len(members))
+	var stale []any
+	for i, member := range members {
+		id := member.Member.(string)
+		fields := cmds[i].Val()
+		joinCode, _ := fields[0].(string)
+		status, _ := fields[3].(string)
+		p2Joined, _ := fields[4].(string)
+		if joinCode == "" || status != "waiting" || p2Joined == "1" {
+			stale = append(stale, id)
+			continue
+		}
+		ruleset, _ := fields[1].(string)
+		hostName, _ := fields[2].(string)
+		entries = append(entries, LobbyEntry{
+			GameID:    id,
+			JoinCode:  joinCode,
+			Ruleset:   ruleset,
+			HostName:  hostName,
+			CreatedAt: time.UnixMilli(int64(member.Score)),
+		})
+	}
+	if len(stale) > 0 {
+		if err := c.client.ZRem(ctx, lobbyKey, stale...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,93 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLobbyListsPublicGames(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := client.CreateGame(ctx, GameOptions{P1UserID: "user-1"}); err != nil {
+		t.Fatalf("create private game: %v", err)
+	}
+	public, err := client.CreateGame(ctx, GameOptions{P1UserID: "user-2", P1Name: "Ada", Visibility: VisibilityPublic})
+	if err != nil {
+		t.Fatalf("create public game: %v", err)
+	}
+	if public.Visibility != VisibilityPublic || public.P1Name != "Ada" {
+		t.Fatalf("expected public game hosted by Ada, got %+v", public)
+	}
+	if _, err := client.CreateGame(ctx, GameOptions{Visibility: "friends"}); err != ErrInvalidVisibility {
+		t.Fatalf("expected invalid visibility, got %v", err)
+	}
+
+	entries, err := client.ListLobby(ctx, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("list lobby: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the public game, got %+v", entries)
+	}
+	entry := entries[0]
+	if entry.GameID != public.ID || entry.JoinCode != public.JoinCode || entry.HostName != "Ada" || entry.Ruleset != public.Rules.Name {
+		t.Fatalf("unexpected lobby entry %+v", entry)
+	}
+
+	if _, _, err := client.JoinGame(ctx, public.JoinCode, "user-3"); err != nil {
+		t.Fatalf("join game: %v", err)
+	}
+	if n := client.client.ZCard(ctx, lobbyKey).Val(); n != 0 {
+		t.Fatalf("expected join to remove lobby entry, %d left", n)
+	}
+}
+
+func TestLobbyDropsOldAndFinishedGames(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	old, err := client.CreateGame(ctx, GameOptions{Visibility: VisibilityPublic})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	created := float64(time.Now().Add(-2 * time.Hour).UnixMilli())
+	if err := client.client.ZAdd(ctx, lobbyKey, redis.Z{Score: created, Member: old.ID}).Err(); err != nil {
+		t.Fatalf("backdate lobby entry: %v", err)
+	}
+	abandoned, err := client.CreateGame(ctx, GameOptions{Visibility: VisibilityPublic})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if _, err := client.Resign(ctx, abandoned.ID, playerOne); err != nil {
+		t.Fatalf("resign: %v", err)
+	}
+	expired, err := client.CreateGame(ctx, GameOptions{Visibility: VisibilityPublic})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if err := client.ExpireGame(ctx, expired.ID, time.Minute); err != nil {
+		t.Fatalf("expire game: %v", err)
+	}
+	gone, err := client.CreateGame(ctx, GameOptions{Visibility: VisibilityPublic})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	client.client.Del(ctx, gameMetaKey(gone.ID))
+
+	entries, err := client.ListLobby(ctx, 10, time.Hour)
+	if err != nil {
+		t.Fatalf("list lobby: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected empty lobby, got %+v", entries)
+	}
+	if n := client.client.ZCard(ctx, lobbyKey).Val(); n != 0 {
+		t.Fatalf("expected stale entries removed, %d left", n)
+	}
+}
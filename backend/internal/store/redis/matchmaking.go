@@ -0,0 +1,148 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	matchQueueKey   = "matchmaking:queue"
+	matchWaitingKey = "matchmaking:waiting"
+)
+
+var ErrQueueExpired = errors.New("matchmaking wait expired")
+
+// MatchWindow is how far apart two ratings may be for a pairing. It starts at
+// Base and grows by Growth per second waited, up to Max. Users waiting longer
+// than MaxWait are dropped from the queue; zero keeps them.
+type MatchWindow struct {
+	Base    float64
+	Growth  float64
+	Max     float64
+	MaxWait time.Duration
+}
+
+// JoinQueue puts userID on the matchmaking queue at rating. Joining again
+// updates the rating but keeps the original wait time. Any match saved from
+// an earlier queue is dropped, so it is not handed over again.
+func (c *Client) JoinQueue(ctx context.Context, userID string, rating float64) error {
+	return joinQueueScript.Run(ctx, c.client, []string{matchQueueKey, matchWaitingKey, matchKey(userID)}, userID, rating).Err()
+}
+
+func (c *Client) LeaveQueue(ctx context.Context, userID string) error {
+	pipe := c.client.TxPipeline()
+	pipe.ZRem(ctx, matchQueueKey, userID)
+	pipe.ZRem(ctx, matchWaitingKey, userID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// QueuedUsers returns up to limit queued users, longest waiting first.
+func (c *Client) QueuedUsers(ctx context.Context, limit int64) ([]string, error) {
+	return c.client.ZRange(ctx, matchWaitingKey, 0, limit-1).Result()
+}
+
+// PairQueued looks for the queued user closest in rating to userID within
+// userID's current window. When one is found both leave the queue in the same
+// script, so concurrent matchmakers never hand out the same user twice. It
+// returns "" when there is no opponent yet or userID is no longer queued, and
+// ErrQueueExpired when userID waited too long and was dropped.
+func (c *Client) PairQueued(ctx context.Context, userID string, window MatchWindow) (string, error) {
+	res, err := pairQueuedScript.Run(ctx, c.client, []string{matchQueueKey, matchWaitingKey},
+		userID, window.Base, window.Growth, window.Max, window.MaxWait.Milliseconds(),
+	).Text()
+	if err != nil {
+		return "", err
+	}
+	if res == "ERR:expired" {
+		return "", ErrQueueExpired
+	}
+	return res, nil
+}
+
+// SaveMatch keeps the match found for userID for ttl so it can be handed over
+// when the user connects after the notification was sent.
+func (c *Client) SaveMatch(ctx context.Context, userID string, data []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, matchKey(userID), data, ttl).Err()
+}
+
+// PendingMatch returns the match saved for userID, or nil if there is none.
+func (c *Client) PendingMatch(ctx context.Context, userID string) ([]byte, error) {
+	data, err := c.client.Get(ctx, matchKey(userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func matchKey(userID string) string {
+	return "matchmaking:match:" + userID
+}
+
+var joinQueueScript = redis.NewScript(`
+local queue = KEYS[1]
+local waiting = KEYS[2]
+local match = KEYS[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('DEL', match)
+redis.call('ZADD', queue, ARGV[2], ARGV[1])
+redis.call('ZADD', waiting, 'NX', now, ARGV[1])
+return 'OK'
+`)
+
+var pairQueuedScript = redis.NewScript(`
+local queue = KEYS[1]
+local waiting = KEYS[2]
+
+local user = ARGV[1]
+local base = tonumber(ARGV[2])
+local growth = tonumber(ARGV[3])
+local max_window = tonumber(ARGV[4])
+local max_wait = tonumber(ARGV[5])
+
+local rating = redis.call('ZSCORE', queue, user)
+if not rating then
+  return ''
+end
+rating = tonumber(rating)
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local waited = now - (tonumber(redis.call('ZSCORE', waiting, user)) or now)
+
+if max_wait > 0 and waited > max_wait then
+  redis.call('ZREM', queue, user)
+  redis.call('ZREM', waiting, user)
+  return 'ERR:expired'
+end
+
+local window = math.min(base + growth * waited / 1000, max_window)
+local candidates = redis.call('ZRANGEBYSCORE', queue, rating - window, rating + window, 'WITHSCORES')
+
+local best = nil
+local best_diff = nil
+for i = 1, #candidates, 2 do
+  local candidate = candidates[i]
+  if candidate ~= user then
+    local diff = math.abs(tonumber(candidates[i + 1]) - rating)
+    if not best or diff < best_diff then
+      best = candidate
+      best_diff = diff
+    end
+  end
+end
+
+if not best then
+  return ''
+end
+
+redis.call('ZREM', queue, user, best)
+redis.call('ZREM', waiting, user, best)
+return best
+`)
@@ -0,0 +1,121 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var testWindow = MatchWindow{Base: 50, Growth: 10, Max: 400}
+
+func rewindQueueWait(t *testing.T, client *Client, userID string, by time.Duration) {
+	ctx := context.Background()
+	since, err := client.client.ZScore(ctx, matchWaitingKey, userID).Result()
+	if err != nil {
+		t.Fatalf("read wait: %v", err)
+	}
+	if err := client.client.ZAdd(ctx, matchWaitingKey, redis.Z{Score: since - float64(by.Milliseconds()), Member: userID}).Err(); err != nil {
+		t.Fatalf("rewind wait: %v", err)
+	}
+}
+
+func TestPairQueuedWidensWindow(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for user, rating := range map[string]float64{"ada": 1500, "grace": 1600} {
+		if err := client.JoinQueue(ctx, user, rating); err != nil {
+			t.Fatalf("join queue: %v", err)
+		}
+	}
+
+	opponent, err := client.PairQueued(ctx, "ada", testWindow)
+	if err != nil || opponent != "" {
+		t.Fatalf("expected no pairing outside window, got %q, %v", opponent, err)
+	}
+
+	rewindQueueWait(t, client, "ada", 6*time.Second)
+	opponent, err = client.PairQueued(ctx, "ada", testWindow)
+	if err != nil || opponent != "grace" {
+		t.Fatalf("expected grace once window widened, got %q, %v", opponent, err)
+	}
+
+	if opponent, err := client.PairQueued(ctx, "grace", testWindow); err != nil || opponent != "" {
+		t.Fatalf("expected grace off the queue, got %q, %v", opponent, err)
+	}
+	users, err := client.QueuedUsers(ctx, 10)
+	if err != nil || len(users) != 0 {
+		t.Fatalf("expected empty queue, got %v, %v", users, err)
+	}
+}
+
+func TestPairQueuedPicksClosestRating(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for user, rating := range map[string]float64{"ada": 1500, "grace": 1540, "linus": 1510} {
+		if err := client.JoinQueue(ctx, user, rating); err != nil {
+			t.Fatalf("join queue: %v", err)
+		}
+	}
+
+	if opponent, err := client.PairQueued(ctx, "ada", testWindow); err != nil || opponent != "linus" {
+		t.Fatalf("expected linus, got %q, %v", opponent, err)
+	}
+	users, _ := client.QueuedUsers(ctx, 10)
+	if len(users) != 1 || users[0] != "grace" {
+		t.Fatalf("expected grace left waiting, got %v", users)
+	}
+}
+
+func TestPairQueuedDropsExpiredWait(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.JoinQueue(ctx, "ada", 1500); err != nil {
+		t.Fatalf("join queue: %v", err)
+	}
+	rewindQueueWait(t, client, "ada", time.Minute)
+
+	window := testWindow
+	window.MaxWait = 30 * time.Second
+	if _, err := client.PairQueued(ctx, "ada", window); err != ErrQueueExpired {
+		t.Fatalf("expected expired wait, got %v", err)
+	}
+	if users, _ := client.QueuedUsers(ctx, 10); len(users) != 0 {
+		t.Fatalf("expected ada dropped, got %v", users)
+	}
+}
+
+func TestJoinQueueDropsPendingMatch(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, user := range []string{"ada", "grace"} {
+		if err := client.JoinQueue(ctx, user, 1500); err != nil {
+			t.Fatalf("join queue: %v", err)
+		}
+	}
+	if opponent, err := client.PairQueued(ctx, "ada", testWindow); err != nil || opponent != "grace" {
+		t.Fatalf("expected grace, got %q, %v", opponent, err)
+	}
+	if err := client.SaveMatch(ctx, "ada", []byte(`{"game_id":"g1"}`), 5*time.Minute); err != nil {
+		t.Fatalf("save match: %v", err)
+	}
+	if data, err := client.PendingMatch(ctx, "ada"); err != nil || data == nil {
+		t.Fatalf("expected the match pending, got %s, %v", data, err)
+	}
+
+	if err := client.JoinQueue(ctx, "ada", 1500); err != nil {
+		t.Fatalf("join queue again: %v", err)
+	}
+	if data, err := client.PendingMatch(ctx, "ada"); err != nil || data != nil {
+		t.Fatalf("expected no stale match after queueing again, got %s, %v", data, err)
+	}
+}
@@ -0,0 +1,133 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// archiveOutboxKey lists finished games waiting to be archived. The scripts
+// that finish a game push onto it in the same call, so no finished game is
+// missed even if the instance that finished it dies right after.
+const (
+	archiveOutboxKey   = "games:archive_outbox"
+	archiveAttemptsKey = "games:archive_attempts"
+	archiveDeadKey     = "games:archive_dead"
+	archiveWorkersKey  = "games:archive_workers"
+)
+
+// ClaimArchive moves the oldest game on the outbox to the processing list of
+// instance and returns its ID. It waits up to wait for one to show up and
+// returns "" if none did. A claimed game stays on the processing list until
+// it is acked or failed.
+func (c *Client) ClaimArchive(ctx context.Context, instance string, wait time.Duration) (string, error) {
+	id, err := c.client.BLMove(ctx, archiveOutboxKey, archiveProcessingKey(instance), "RIGHT", "LEFT", wait).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return id, err
+}
+
+// AckArchive removes a game that was archived from the processing list of
+// instance.
+func (c *Client) AckArchive(ctx context.Context, instance string, gameID string) error {
+	pipe := c.client.TxPipeline()
+	pipe.LRem(ctx, archiveProcessingKey(instance), 1, gameID)
+	pipe.HDel(ctx, archiveAttemptsKey, gameID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// FailArchive records a failed attempt to archive gameID. The game goes back
+// on the outbox until it has failed maxFailures times; then it is moved to
+// the dead-letter list and dead is true.
+func (c *Client) FailArchive(ctx context.Context, instance string, gameID string, maxFailures int) (bool, error) {
+	dead, err := failArchiveScript.Run(ctx, c.client, []string{
+		archiveProcessingKey(instance),
+		archiveAttemptsKey,
+		archiveOutboxKey,
+		archiveDeadKey,
+	}, gameID, maxFailures).Int()
+	return dead == 1, err
+}
+
+// RequeueArchives puts the games instance had claimed back on the outbox. It
+// is meant for startup, when anything still on the processing list was left
+// behind by a previous run that stopped mid-archive.
+func (c *Client) RequeueArchives(ctx context.Context, instance string) (int, error) {
+	count := 0
+	for {
+		_, err := c.client.LMove(ctx, archiveProcessingKey(instance), archiveOutboxKey, "RIGHT", "RIGHT").Result()
+		if errors.Is(err, redis.Nil) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// HeartbeatArchiver records that the archive worker of instance is alive as
+// of now.
+func (c *Client) HeartbeatArchiver(ctx context.Context, instance string, now time.Time) error {
+	return c.client.ZAdd(ctx, archiveWorkersKey, redis.Z{Score: float64(now.UnixMilli()), Member: instance}).Err()
+}
+
+// ReapArchives requeues the games claimed by every instance whose last
+// heartbeat is older than staleBefore and forgets those instances. It covers
+// instances that never come back under the same name. A worker that was only
+// slow may archive a game that was also requeued; archiving is idempotent.
+func (c *Client) ReapArchives(ctx context.Context, staleBefore time.Time) (int, error) {
+	instances, err := c.client.ZRangeByScore(ctx, archiveWorkersKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("(%d", staleBefore.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, instance := range instances {
+		n, err := c.RequeueArchives(ctx, instance)
+		count += n
+		if err != nil {
+			return count, err
+		}
+		if err := c.client.ZRem(ctx, archiveWorkersKey, instance).Err(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// DeadArchives returns the games that could not be archived, newest first.
+func (c *Client) DeadArchives(ctx context.Context) ([]string, error) {
+	return c.client.LRange(ctx, archiveDeadKey, 0, -1).Result()
+}
+
+func archiveProcessingKey(instance string) string {
+	return "games:archive_processing:" + instance
+}
+
+var failArchiveScript = redis.NewScript(`
+local processing = KEYS[1]
+local attempts = KEYS[2]
+local outbox = KEYS[3]
+local dead = KEYS[4]
+
+local game_id = ARGV[1]
+local max_failures = tonumber(ARGV[2])
+
+redis.call('LREM', processing, 1, game_id)
+if redis.call('HINCRBY', attempts, game_id, 1) >= max_failures then
+  redis.call('HDEL', attempts, game_id)
+  redis.call('LPUSH', dead, game_id)
+  return 1
+end
+redis.call('LPUSH', outbox, game_id)
+return 0
+`)
@@ -0,0 +1,129 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFinishedGameQueuedForArchive(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+	if _, err := client.Resign(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+
+	id, err := client.ClaimArchive(ctx, "a", time.Millisecond)
+	if err != nil {
+		t.Fatalf("claim error: %v", err)
+	}
+	if id != meta.ID {
+		t.Fatalf("expected %s claimed, got %q", meta.ID, id)
+	}
+
+	if id, err := client.ClaimArchive(ctx, "b", time.Millisecond); err != nil || id != "" {
+		t.Fatalf("expected empty outbox, got %q, %v", id, err)
+	}
+
+	if err := client.AckArchive(ctx, "a", meta.ID); err != nil {
+		t.Fatalf("ack error: %v", err)
+	}
+	if n, err := client.RequeueArchives(ctx, "a"); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to requeue, got %d, %v", n, err)
+	}
+}
+
+func TestFailArchiveRetriesThenDeadLetters(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.client.LPush(ctx, archiveOutboxKey, "game").Err(); err != nil {
+		t.Fatalf("seed outbox: %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		id, err := client.ClaimArchive(ctx, "a", time.Millisecond)
+		if err != nil || id != "game" {
+			t.Fatalf("attempt %d: expected game claimed, got %q, %v", attempt, id, err)
+		}
+		dead, err := client.FailArchive(ctx, "a", id, 3)
+		if err != nil {
+			t.Fatalf("fail error: %v", err)
+		}
+		if dead != (attempt == 3) {
+			t.Fatalf("attempt %d: unexpected dead=%v", attempt, dead)
+		}
+	}
+
+	if id, _ := client.ClaimArchive(ctx, "a", time.Millisecond); id != "" {
+		t.Fatalf("expected dead game off the outbox, got %q", id)
+	}
+	dead, err := client.DeadArchives(ctx)
+	if err != nil {
+		t.Fatalf("dead archives error: %v", err)
+	}
+	if len(dead) != 1 || dead[0] != "game" {
+		t.Fatalf("expected game in dead letters, got %v", dead)
+	}
+}
+
+func TestRequeueArchivesRestoresClaimed(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.client.LPush(ctx, archiveOutboxKey, "first", "second").Err(); err != nil {
+		t.Fatalf("seed outbox: %v", err)
+	}
+	if _, err := client.ClaimArchive(ctx, "a", time.Millisecond); err != nil {
+		t.Fatalf("claim error: %v", err)
+	}
+
+	n, err := client.RequeueArchives(ctx, "a")
+	if err != nil || n != 1 {
+		t.Fatalf("expected one game requeued, got %d, %v", n, err)
+	}
+	if id, _ := client.ClaimArchive(ctx, "b", time.Millisecond); id != "first" {
+		t.Fatalf("expected requeued game claimed first, got %q", id)
+	}
+}
+
+func TestReapArchivesRequeuesStaleInstances(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := client.client.LPush(ctx, archiveOutboxKey, "gone-game", "live-game").Err(); err != nil {
+		t.Fatalf("seed outbox: %v", err)
+	}
+	now := time.Now()
+	for _, instance := range []string{"gone", "live"} {
+		if _, err := client.ClaimArchive(ctx, instance, time.Millisecond); err != nil {
+			t.Fatalf("claim error: %v", err)
+		}
+	}
+	if err := client.HeartbeatArchiver(ctx, "gone", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("heartbeat error: %v", err)
+	}
+	if err := client.HeartbeatArchiver(ctx, "live", now); err != nil {
+		t.Fatalf("heartbeat error: %v", err)
+	}
+
+	n, err := client.ReapArchives(ctx, now.Add(-time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("expected one game reaped, got %d, %v", n, err)
+	}
+	if id, _ := client.ClaimArchive(ctx, "b", time.Millisecond); id != "gone-game" {
+		t.Fatalf("expected reaped game back on the outbox, got %q", id)
+	}
+	if claimed, _ := client.client.LRange(ctx, archiveProcessingKey("live"), 0, -1).Result(); len(claimed) != 1 {
+		t.Fatalf("expected live instance to keep its claim, got %v", claimed)
+	}
+	if workers, _ := client.client.ZRange(ctx, archiveWorkersKey, 0, -1).Result(); len(workers) != 1 || workers[0] != "live" {
+		t.Fatalf("expected stale instance forgotten, got %v", workers)
+	}
+}
@@ -0,0 +1,117 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Presence is tracked per open connection so a player with two tabs, or
+// connections on two instances, stays present until the last one closes.
+// Entries are scored by when they lapse; a connection that dies without
+// saying goodbye drops out once its heartbeats stop. Spectators are tracked
+// the same way under PresenceSpectator.
+
+// PresenceSpectator takes the place of the player for spectator connections.
+const PresenceSpectator = "spectator"
+
+// JoinPresence records connID of player in gameID for ttl. It reports
+// whether this is the player's only live connection, i.e. they just came
+// online.
+func (c *Client) JoinPresence(ctx context.Context, gameID, player, connID string, ttl time.Duration) (bool, error) {
+	n, err := joinPresenceScript.Run(ctx, c.client, []string{presenceKey(gameID)}, player, connID, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// TouchPresence extends connID's entry by ttl.
+func (c *Client) TouchPresence(ctx context.Context, gameID, player, connID string, ttl time.Duration) error {
+	_, err := joinPresenceScript.Run(ctx, c.client, []string{presenceKey(gameID)}, player, connID, ttl.Milliseconds()).Int()
+	return err
+}
+
+// LeavePresence removes connID and reports whether player has no live
+// connection left, i.e. they just went offline.
+func (c *Client) LeavePresence(ctx context.Context, gameID, player, connID string) (bool, error) {
+	n, err := leavePresenceScript.Run(ctx, c.client, []string{presenceKey(gameID)}, player, connID).Int()
+	return n == 1, err
+}
+
+// Presence returns which players of gameID have a live connection.
+func (c *Client) Presence(ctx context.Context, gameID string) (map[string]bool, error) {
+	members, err := presenceScript.Run(ctx, c.client, []string{presenceKey(gameID)}).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	presence := map[string]bool{playerOne: false, playerTwo: false}
+	for _, member := range members {
+		player, _, _ := strings.Cut(member, ":")
+		if _, ok := presence[player]; ok {
+			presence[player] = true
+		}
+	}
+	return presence, nil
+}
+
+// HasSpectators reports whether gameID has a live spectator connection.
+func (c *Client) HasSpectators(ctx context.Context, gameID string) (bool, error) {
+	members, err := presenceScript.Run(ctx, c.client, []string{presenceKey(gameID)}).StringSlice()
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if strings.HasPrefix(member, PresenceSpectator+":") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func presenceKey(id string) string {
+	return fmt.Sprintf("game:%s:presence", id)
+}
+
+const luaPresence = `
+local function presence_now()
+  local t = redis.call('TIME')
+  return tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+end
+
+local function player_online(key, player)
+  for _, member in ipairs(redis.call('ZRANGE', key, 0, -1)) do
+    if string.sub(member, 1, #player + 1) == player .. ':' then
+      return true
+    end
+  end
+  return false
+end
+`
+
+var joinPresenceScript = redis.NewScript(luaPresence + `
+local key = KEYS[1]
+local player = ARGV[1]
+local member = player .. ':' .. ARGV[2]
+local ttl = tonumber(ARGV[3])
+
+local now = presence_now()
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+local first = not player_online(key, player)
+redis.call('ZADD', key, now + ttl, member)
+redis.call('PEXPIRE', key, ttl)
+return first and 1 or 0
+`)
+
+var leavePresenceScript = redis.NewScript(luaPresence + `
+local key = KEYS[1]
+local player = ARGV[1]
+
+redis.call('ZREM', key, player .. ':' .. ARGV[2])
+redis.call('ZREMRANGEBYSCORE', key, '-inf', presence_now())
+return player_online(key, player) and 0 or 1
+`)
+
+var presenceScript = redis.NewScript(luaPresence + `
+return redis.call('ZRANGEBYSCORE', KEYS[1], '(' .. presence_now(), '+inf')
+`)
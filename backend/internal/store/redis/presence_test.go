@@ -0,0 +1,83 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestPresenceCountsConnections(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := NewClient(Config{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	for i, step := range []struct {
+		conn  string
+		first bool
+	}{{"a", true}, {"b", false}} {
+		first, err := client.JoinPresence(ctx, "game", playerOne, step.conn, time.Minute)
+		if err != nil || first != step.first {
+			t.Fatalf("join %d: expected first=%v, got %v %v", i, step.first, first, err)
+		}
+	}
+	presence, err := client.Presence(ctx, "game")
+	if err != nil || !presence[playerOne] || presence[playerTwo] {
+		t.Fatalf("expected only p1 present, got %v %v", presence, err)
+	}
+
+	if last, err := client.LeavePresence(ctx, "game", playerOne, "a"); err != nil || last {
+		t.Fatalf("expected p1 still online, got %v %v", last, err)
+	}
+	if last, err := client.LeavePresence(ctx, "game", playerOne, "b"); err != nil || !last {
+		t.Fatalf("expected p1 offline, got %v %v", last, err)
+	}
+
+	if _, err := client.JoinPresence(ctx, "game", playerTwo, "c", time.Minute); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	server.SetTime(now.Add(50 * time.Second))
+	if err := client.TouchPresence(ctx, "game", playerTwo, "c", time.Minute); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	server.SetTime(now.Add(100 * time.Second))
+	if presence, _ := client.Presence(ctx, "game"); !presence[playerTwo] || presence[playerOne] {
+		t.Fatalf("expected heartbeat to keep p2 present, got %v", presence)
+	}
+	server.SetTime(now.Add(3 * time.Minute))
+	if presence, _ := client.Presence(ctx, "game"); presence[playerTwo] {
+		t.Fatalf("expected p2 to lapse without heartbeats, got %v", presence)
+	}
+}
+
+func TestSpectatorsAreNotPlayers(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if watched, err := client.HasSpectators(ctx, "game"); err != nil || watched {
+		t.Fatalf("expected no spectators, got %v %v", watched, err)
+	}
+	if _, err := client.JoinPresence(ctx, "game", PresenceSpectator, "w", time.Minute); err != nil {
+		t.Fatalf("join error: %v", err)
+	}
+	if watched, err := client.HasSpectators(ctx, "game"); err != nil || !watched {
+		t.Fatalf("expected a spectator, got %v %v", watched, err)
+	}
+	presence, err := client.Presence(ctx, "game")
+	if err != nil || len(presence) != 2 || presence[playerOne] || presence[playerTwo] {
+		t.Fatalf("expected spectators left out of presence, got %+v %v", presence, err)
+	}
+
+	if _, err := client.LeavePresence(ctx, "game", PresenceSpectator, "w"); err != nil {
+		t.Fatalf("leave error: %v", err)
+	}
+	if watched, _ := client.HasSpectators(ctx, "game"); watched {
+		t.Fatalf("expected spectator gone")
+	}
+}
@@ -0,0 +1,62 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+)
+
+const subscriptionBuffer = 64
+
+// PubSub relays game messages between backend instances over one Redis
+// channel per game. Messages published while an instance is not subscribed
+// are not replayed.
+type PubSub struct {
+	client *Client
+}
+
+func NewPubSub(client *Client) *PubSub {
+	return &PubSub{client: client}
+}
+
+func (p *PubSub) Publish(ctx context.Context, gameID string, data []byte) error {
+	return p.client.client.Publish(ctx, gameChannel(gameID), data).Err()
+}
+
+// Subscribe returns the messages published for gameID until ctx is done, at
+// which point the channel is closed. It only returns once Redis confirmed the
+// subscription, so nothing published afterwards is missed.
+func (p *PubSub) Subscribe(ctx context.Context, gameID string) (<-chan []byte, error) {
+	sub := p.client.client.Subscribe(ctx, gameChannel(gameID))
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	out := make(chan []byte, subscriptionBuffer)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func gameChannel(id string) string {
+	return fmt.Sprintf("game:%s:ws", id)
+}
@@ -0,0 +1,46 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPubSubDeliversPerGame(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	pubsub := NewPubSub(client)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	messages, err := pubsub.Subscribe(ctx, "game-1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+
+	if err := pubsub.Publish(context.Background(), "game-2", []byte("other")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+	if err := pubsub.Publish(context.Background(), "game-1", []byte("hello")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+
+	select {
+	case data := <-messages:
+		if string(data) != "hello" {
+			t.Fatalf("expected hello, got %q", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for message")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Fatalf("expected channel closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for close")
+	}
+}
@@ -0,0 +1,143 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrGameNotFinished  = errors.New("game not finished")
+	ErrRematchStarted   = errors.New("rematch already started")
+	ErrNoRematchRequest = errors.New("no rematch requested by opponent")
+)
+
+// RequestRematch records that player wants to play gameID again. Requesting
+// twice, or after the opponent already asked, is allowed; the game is only
+// created by AcceptRematch.
+func (c *Client) RequestRematch(ctx context.Context, gameID string, player string) error {
+	if player != playerOne && player != playerTwo {
+		return ErrInvalidPlayer
+	}
+
+	res, err := requestRematchScript.Run(ctx, c.client, []string{gameMetaKey(gameID)}, player).Result()
+	if err != nil {
+		return err
+	}
+	return rematchError(res)
+}
+
+// AcceptRematch creates the follow-up game once the opponent has requested
+// it. Players keep their seats and the other player starts. Only the first
+// accept wins the claim on the finished game, so a rematch is created at most
+// once.
+func (c *Client) AcceptRematch(ctx context.Context, gameID string, player string) (GameMeta, error) {
+	if player != playerOne && player != playerTwo {
+		return GameMeta{}, ErrInvalidPlayer
+	}
+
+	previous, err := c.GetMeta(ctx, gameID)
+	if err != nil {
+		return GameMeta{}, err
+	}
+
+	id, err := randomHex(12)
+	if err != nil {
+		return GameMeta{}, err
+	}
+
+	metaKey := gameMetaKey(gameID)
+	res, err := acceptRematchScript.Run(ctx, c.client, []string{metaKey}, player, id).Result()
+	if err != nil {
+		return GameMeta{}, err
+	}
+	if err := rematchError(res); err != nil {
+		return GameMeta{}, err
+	}
+
+	meta, err := c.createGame(ctx, id, GameOptions{
+		Ruleset:        previous.Rules,
+		Opponent:       previous.Opponent,
+		Difficulty:     previous.Difficulty,
+		TurnTimeout:    previous.TurnTimeout,
+		TimeoutAction:  previous.TimeoutAction,
+		TimeBank:       previous.Clock.TimeBank,
+		Increment:      previous.Clock.Increment,
+		StartingPlayer: opponent(previous.StartingPlayer),
+		P1UserID:       previous.P1UserID,
+		P2UserID:       previous.P2UserID,
+		RevealDelay:    previous.RevealDelay,
+	}, true)
+	if err != nil {
+		_ = c.client.HDel(ctx, metaKey, "rematch_game").Err()
+		return GameMeta{}, err
+	}
+	return meta, nil
+}
+
+func rematchError(res any) error {
+	resultStr, ok := res.(string)
+	if !ok {
+		return errUnexpectedResponse
+	}
+	switch resultStr {
+	case "OK":
+		return nil
+	case "ERR:game_not_found":
+		return ErrGameNotFound
+	case "ERR:game_not_finished":
+		return ErrGameNotFinished
+	case "ERR:rematch_started":
+		return ErrRematchStarted
+	case "ERR:no_rematch_request":
+		return ErrNoRematchRequest
+	default:
+		return errors.New(strings.TrimPrefix(resultStr, "ERR:"))
+	}
+}
+
+var requestRematchScript = redis.NewScript(`
+local meta = KEYS[1]
+
+local player = ARGV[1]
+
+if redis.call('EXISTS', meta) == 0 then
+  return 'ERR:game_not_found'
+end
+if redis.call('HGET', meta, 'status') ~= 'finished' then
+  return 'ERR:game_not_finished'
+end
+if redis.call('HEXISTS', meta, 'rematch_game') == 1 then
+  return 'ERR:rematch_started'
+end
+
+redis.call('HSET', meta, 'rematch_requested_by', player)
+return 'OK'
+`)
+
+var acceptRematchScript = redis.NewScript(`
+local meta = KEYS[1]
+
+local player = ARGV[1]
+local rematch_id = ARGV[2]
+
+if redis.call('EXISTS', meta) == 0 then
+  return 'ERR:game_not_found'
+end
+if redis.call('HGET', meta, 'status') ~= 'finished' then
+  return 'ERR:game_not_finished'
+end
+if redis.call('HEXISTS', meta, 'rematch_game') == 1 then
+  return 'ERR:rematch_started'
+end
+
+local requested_by = redis.call('HGET', meta, 'rematch_requested_by')
+if not requested_by or requested_by == '' or requested_by == player then
+  return 'ERR:no_rematch_request'
+end
+
+redis.call('HSET', meta, 'rematch_game', rematch_id)
+return 'OK'
+`)
@@ -0,0 +1,92 @@
+package redisstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRematchFlow(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutRandomShot)
+
+	if err := client.RequestRematch(ctx, meta.ID, playerOne); err != ErrGameNotFinished {
+		t.Fatalf("expected game not finished, got %v", err)
+	}
+	if _, err := client.Resign(ctx, meta.ID, playerTwo); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+
+	if _, err := client.AcceptRematch(ctx, meta.ID, playerTwo); err != ErrNoRematchRequest {
+		t.Fatalf("expected no rematch request, got %v", err)
+	}
+	if err := client.RequestRematch(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("request rematch error: %v", err)
+	}
+	if _, err := client.AcceptRematch(ctx, meta.ID, playerOne); err != ErrNoRematchRequest {
+		t.Fatalf("expected requester unable to accept, got %v", err)
+	}
+
+	rematch, err := client.AcceptRematch(ctx, meta.ID, playerTwo)
+	if err != nil {
+		t.Fatalf("accept rematch error: %v", err)
+	}
+	if rematch.ID == meta.ID || rematch.JoinCode != "" || !rematch.P2Joined {
+		t.Fatalf("expected new seated game, got %+v", rematch)
+	}
+	if rematch.Turn != playerTwo || rematch.StartingPlayer != playerTwo {
+		t.Fatalf("expected p2 to start the rematch, got %+v", rematch)
+	}
+	if rematch.TurnTimeout != 30*time.Second || rematch.TimeoutAction != TimeoutRandomShot {
+		t.Fatalf("expected turn settings carried over, got %+v", rematch)
+	}
+
+	previous, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if previous.RematchGameID != rematch.ID {
+		t.Fatalf("expected rematch linked from previous game, got %q", previous.RematchGameID)
+	}
+	if err := client.RequestRematch(ctx, meta.ID, playerOne); err != ErrRematchStarted {
+		t.Fatalf("expected rematch started, got %v", err)
+	}
+}
+
+func TestAcceptRematchCreatesOnce(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+	if _, err := client.Resign(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+	if err := client.RequestRematch(ctx, meta.ID, playerOne); err != nil {
+		t.Fatalf("request rematch error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.AcceptRematch(ctx, meta.ID, playerTwo); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Fatalf("expected exactly one rematch, got %d", created)
+	}
+}
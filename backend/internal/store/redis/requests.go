@@ -0,0 +1,44 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Client request IDs make retried moves idempotent. The first attempt claims
+// the ID with an empty value and stores its reply when done, so a retry gets
+// that reply back instead of acting again.
+
+// ClaimRequest reserves requestID for player in gameID for ttl and reports
+// whether it was new. For an ID seen before it returns the stored reply,
+// which is empty while the first attempt is still being handled.
+func (c *Client) ClaimRequest(ctx context.Context, gameID, player, requestID string, ttl time.Duration) ([]byte, bool, error) {
+	key := requestKey(gameID, player, requestID)
+	claimed, err := c.client.SetNX(ctx, key, "", ttl).Result()
+	if err != nil || claimed {
+		return nil, claimed, err
+	}
+	reply, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	return reply, false, err
+}
+
+// CompleteRequest stores the reply to a claimed request for the rest of its
+// ttl. An empty reply releases the claim so the request can be retried.
+func (c *Client) CompleteRequest(ctx context.Context, gameID, player, requestID string, reply []byte) error {
+	key := requestKey(gameID, player, requestID)
+	if len(reply) == 0 {
+		return c.client.Del(ctx, key).Err()
+	}
+	return c.client.SetArgs(ctx, key, reply, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+}
+
+func requestKey(gameID, player, requestID string) string {
+	return fmt.Sprintf("game:%s:request:%s:%s", gameID, player, requestID)
+}
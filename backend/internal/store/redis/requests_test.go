@@ -0,0 +1,46 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestClaimRequestReturnsStoredReply(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := NewClient(Config{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, claimed, err := client.ClaimRequest(ctx, "g1", playerOne, "r1", time.Minute); err != nil || !claimed {
+		t.Fatalf("expected first claim, got claimed=%v err=%v", claimed, err)
+	}
+	reply, claimed, err := client.ClaimRequest(ctx, "g1", playerOne, "r1", time.Minute)
+	if err != nil || claimed || len(reply) != 0 {
+		t.Fatalf("expected pending request, got %q claimed=%v err=%v", reply, claimed, err)
+	}
+	if _, claimed, _ := client.ClaimRequest(ctx, "g1", playerTwo, "r1", time.Minute); !claimed {
+		t.Fatalf("expected request ids to be per player")
+	}
+
+	if err := client.CompleteRequest(ctx, "g1", playerOne, "r1", []byte(`{"type":"ack"}`)); err != nil {
+		t.Fatalf("complete request: %v", err)
+	}
+	reply, claimed, err = client.ClaimRequest(ctx, "g1", playerOne, "r1", time.Minute)
+	if err != nil || claimed || string(reply) != `{"type":"ack"}` {
+		t.Fatalf("expected stored reply, got %q claimed=%v err=%v", reply, claimed, err)
+	}
+
+	server.FastForward(time.Minute)
+	if _, claimed, _ := client.ClaimRequest(ctx, "g1", playerOne, "r1", time.Minute); !claimed {
+		t.Fatalf("expected request id to expire")
+	}
+	if err := client.CompleteRequest(ctx, "g1", playerOne, "r1", nil); err != nil {
+		t.Fatalf("release request: %v", err)
+	}
+	if _, claimed, _ := client.ClaimRequest(ctx, "g1", playerOne, "r1", time.Minute); !claimed {
+		t.Fatalf("expected released request to be claimable")
+	}
+}
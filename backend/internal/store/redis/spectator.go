@@ -0,0 +1,180 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"shipsgame/internal/game"
+)
+
+// SpectatorState is what watchers of a game see live: the shots each player
+// fired and the ships each player has lost. Ships still afloat are never in
+// it. Maps are keyed by player.
+type SpectatorState struct {
+	GameID       string
+	Opponent     string
+	Turn         string
+	TurnDeadline time.Time
+	Status       string
+	Winner       string
+	FinishReason string
+	Clock        Clock
+	Rules        game.Ruleset
+	Shots        map[string]map[string]string
+	SunkShips    map[string]map[string][][]int
+	RevealDelay  time.Duration
+
+	// EventSeq is the seq of the last event logged when the game was read.
+	EventSeq int64
+}
+
+// RevealState is the game as it stood at AsOf. It is rebuilt from the event
+// log so it never shows a shot fired after AsOf. Both fleets are only in it
+// once the game has finished; until then Ships holds just the ships sunk by
+// AsOf, as fleets never move and a delayed view of them would give away the
+// live board.
+type RevealState struct {
+	GameID       string
+	AsOf         time.Time
+	Status       string
+	Winner       string
+	FinishReason string
+	Shots        map[string]map[string]string
+	Ships        map[string]map[string][][]int
+}
+
+var ErrRevealDisabled = errors.New("reveal disabled for game")
+
+func (c *Client) GetSpectatorState(ctx context.Context, gameID string) (SpectatorState, error) {
+	meta, err := c.GetMeta(ctx, gameID)
+	if err != nil {
+		return SpectatorState{}, err
+	}
+
+	state := SpectatorState{
+		GameID:       meta.ID,
+		Opponent:     meta.Opponent,
+		Turn:         meta.Turn,
+		TurnDeadline: meta.TurnDeadline,
+		Status:       meta.Status,
+		Winner:       meta.Winner,
+		FinishReason: meta.FinishReason,
+		Clock:        meta.Clock,
+		Rules:        meta.Rules,
+		Shots:        map[string]map[string]string{},
+		SunkShips:    map[string]map[string][][]int{},
+		RevealDelay:  meta.RevealDelay,
+		EventSeq:     meta.EventSeq,
+	}
+	for _, player := range []string{playerOne, playerTwo} {
+		shots, err := c.client.HGetAll(ctx, shotsKey(gameID, player)).Result()
+		if err != nil {
+			return SpectatorState{}, err
+		}
+		state.Shots[player] = shots
+
+		ships, err := c.boardShips(ctx, gameID, player)
+		if err != nil {
+			return SpectatorState{}, err
+		}
+		health, err := c.client.HGetAll(ctx, shipsKey(gameID, player)).Result()
+		if err != nil {
+			return SpectatorState{}, err
+		}
+		sunk := map[string][][]int{}
+		for shipType, cells := range ships {
+			if remaining, ok := health[shipType]; ok && remaining == "0" {
+				sunk[shipType] = cells
+			}
+		}
+		state.SunkShips[player] = sunk
+	}
+	return state, nil
+}
+
+// GetRevealState rebuilds gameID as of asOf. It returns ErrRevealDisabled
+// for games created without a reveal delay, so callers can't reveal fleets
+// of an ordinary game by accident.
+//
+// The full reveal is deliberately held back until the game is over. While it
+// runs, the reveal is only the spectator view as of asOf: shots and sunk
+// ships, which is never more than the live spectator_state already shows.
+// Ships don't move, so any afloat ship in a delayed view would be its live
+// position too, and a stream of it would let players cheat.
+func (c *Client) GetRevealState(ctx context.Context, gameID string, asOf time.Time) (RevealState, error) {
+	meta, err := c.GetMeta(ctx, gameID)
+	if err != nil {
+		return RevealState{}, err
+	}
+	if meta.RevealDelay <= 0 {
+		return RevealState{}, ErrRevealDisabled
+	}
+	events, err := c.GetEvents(ctx, gameID, 0)
+	if err != nil {
+		return RevealState{}, err
+	}
+
+	state := RevealState{
+		GameID: meta.ID,
+		AsOf:   asOf,
+		Status: "waiting",
+		Shots:  map[string]map[string]string{playerOne: {}, playerTwo: {}},
+		Ships:  map[string]map[string][][]int{},
+	}
+	placed := map[string]bool{}
+	sunk := map[string]map[string]bool{playerOne: {}, playerTwo: {}}
+	cutoff := asOf.UnixMilli()
+	for _, event := range events {
+		if event.At > cutoff {
+			continue
+		}
+		switch event.Type {
+		case EventPlaced:
+			placed[event.Player] = true
+			state.Status = "placing"
+			if placed[playerOne] && placed[playerTwo] {
+				state.Status = "active"
+			}
+		case EventShot:
+			state.Shots[event.Player][event.Coord] = event.Outcome
+		case EventSunk:
+			state.Shots[event.Player][event.Coord] = "sunk:" + event.Ship
+			sunk[opponent(event.Player)][event.Ship] = true
+		case EventFinished:
+			state.Status = "finished"
+			state.Winner = event.Winner
+			state.FinishReason = event.Reason
+		}
+	}
+
+	for player := range placed {
+		ships, err := c.boardShips(ctx, gameID, player)
+		if err != nil {
+			return RevealState{}, err
+		}
+		if meta.Status != "finished" {
+			for shipType := range ships {
+				if !sunk[player][shipType] {
+					delete(ships, shipType)
+				}
+			}
+		}
+		state.Ships[player] = ships
+	}
+	return state, nil
+}
+
+func (c *Client) boardShips(ctx context.Context, gameID, player string) (map[string][][]int, error) {
+	raw, err := c.client.HGet(ctx, boardKey(gameID, player), "ships").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	ships := map[string][][]int{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &ships)
+	}
+	return ships, nil
+}
@@ -0,0 +1,187 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/game"
+)
+
+func startSpectatedGame(t *testing.T, client *Client, revealDelay time.Duration) GameMeta {
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{RevealDelay: revealDelay})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+		game.Cruiser:   {{Row: 8, Col: 0}, {Row: 8, Col: 1}, {Row: 8, Col: 2}},
+	}); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+	return meta
+}
+
+func TestSpectatorStateHidesAfloatShips(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startSpectatedGame(t, client, 0)
+	for _, shot := range []struct {
+		player string
+		coord  game.Coord
+	}{
+		{playerOne, game.Coord{Row: 5, Col: 5}},
+		{playerTwo, game.Coord{Row: 3, Col: 3}},
+		{playerOne, game.Coord{Row: 5, Col: 6}},
+	} {
+		if _, err := client.Fire(ctx, meta.ID, shot.player, shot.coord); err != nil {
+			t.Fatalf("fire error: %v", err)
+		}
+	}
+
+	state, err := client.GetSpectatorState(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("spectator state error: %v", err)
+	}
+	if state.Shots[playerOne]["5,6"] != "sunk:destroyer" || state.Shots[playerTwo]["3,3"] != "miss" {
+		t.Fatalf("unexpected shots %+v", state.Shots)
+	}
+	if len(state.SunkShips[playerTwo]) != 1 || len(state.SunkShips[playerTwo]["destroyer"]) != 2 {
+		t.Fatalf("expected only p2's destroyer revealed, got %+v", state.SunkShips[playerTwo])
+	}
+	if len(state.SunkShips[playerOne]) != 0 {
+		t.Fatalf("expected no p1 ships revealed, got %+v", state.SunkShips[playerOne])
+	}
+
+	if _, err := client.GetRevealState(ctx, meta.ID, time.Now()); err != ErrRevealDisabled {
+		t.Fatalf("expected reveal disabled, got %v", err)
+	}
+}
+
+func TestRevealStateIsDelayed(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := NewClient(Config{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server.SetTime(start)
+	meta := startSpectatedGame(t, client, time.Minute)
+
+	server.SetTime(start.Add(30 * time.Second))
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 5, Col: 5}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+
+	before, err := client.GetRevealState(ctx, meta.ID, start.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if before.Status != "waiting" || len(before.Ships) != 0 {
+		t.Fatalf("expected nothing revealed before the game, got %+v", before)
+	}
+
+	placed, err := client.GetRevealState(ctx, meta.ID, start.Add(10*time.Second))
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if placed.Status != "active" || len(placed.Ships[playerTwo]) != 0 || len(placed.Shots[playerOne]) != 0 {
+		t.Fatalf("expected no afloat ships and no shots while the game runs, got %+v", placed)
+	}
+
+	after, err := client.GetRevealState(ctx, meta.ID, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if after.Shots[playerOne]["5,5"] != "hit" {
+		t.Fatalf("expected the shot revealed, got %+v", after.Shots)
+	}
+
+	server.SetTime(start.Add(2 * time.Minute))
+	for _, shot := range []struct {
+		player string
+		coord  game.Coord
+	}{
+		{playerTwo, game.Coord{Row: 9, Col: 9}},
+		{playerOne, game.Coord{Row: 5, Col: 6}},
+	} {
+		if _, err := client.Fire(ctx, meta.ID, shot.player, shot.coord); err != nil {
+			t.Fatalf("fire error: %v", err)
+		}
+	}
+	sunk, err := client.GetRevealState(ctx, meta.ID, start.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if len(sunk.Ships[playerTwo]) != 1 || len(sunk.Ships[playerTwo]["destroyer"]) != 2 || len(sunk.Ships[playerOne]) != 0 {
+		t.Fatalf("expected only the sunk destroyer revealed, got %+v", sunk.Ships)
+	}
+
+	if _, err := client.Resign(ctx, meta.ID, playerTwo); err != nil {
+		t.Fatalf("resign error: %v", err)
+	}
+	finished, err := client.GetRevealState(ctx, meta.ID, start.Add(10*time.Second))
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if finished.Status != "active" || len(finished.Ships[playerOne]) != 1 || len(finished.Ships[playerTwo]) != 2 {
+		t.Fatalf("expected both fleets once the game is over, got %+v", finished)
+	}
+}
+
+func TestRevealShowsNoMoreThanLiveViewUntilFinished(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := NewClient(Config{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	server.SetTime(start)
+	meta := startSpectatedGame(t, client, time.Minute)
+	for _, shot := range []struct {
+		player string
+		coord  game.Coord
+	}{
+		{playerOne, game.Coord{Row: 5, Col: 5}},
+		{playerTwo, game.Coord{Row: 9, Col: 9}},
+		{playerOne, game.Coord{Row: 5, Col: 6}},
+	} {
+		if _, err := client.Fire(ctx, meta.ID, shot.player, shot.coord); err != nil {
+			t.Fatalf("fire error: %v", err)
+		}
+	}
+
+	live, err := client.GetSpectatorState(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("spectator state error: %v", err)
+	}
+	reveal, err := client.GetRevealState(ctx, meta.ID, start)
+	if err != nil {
+		t.Fatalf("reveal state error: %v", err)
+	}
+	if reveal.Status != "active" {
+		t.Fatalf("expected the game still running, got %s", reveal.Status)
+	}
+	for _, player := range []string{playerOne, playerTwo} {
+		for shipType := range reveal.Ships[player] {
+			if _, ok := live.SunkShips[player][shipType]; !ok {
+				t.Fatalf("reveal shows %s's %s, which the live view hides", player, shipType)
+			}
+		}
+	}
+	if _, ok := reveal.Ships[playerTwo]["cruiser"]; ok {
+		t.Fatalf("expected the afloat cruiser held back, got %+v", reveal.Ships)
+	}
+	if len(reveal.Ships[playerOne]) != 0 {
+		t.Fatalf("expected p1's afloat fleet held back, got %+v", reveal.Ships[playerOne])
+	}
+}
@@ -2,19 +2,29 @@ package redisstore
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"time"
 
-	"github.com/redis/go-redis/v9"
+	"shipsgame/internal/game"
 )
 
 type GameState struct {
-	GameID string
-	Turn   string
-	Status string
-	Winner string
-	Shots  map[string]string
-	Ships  map[string][][]int
+	GameID        string
+	Player        string
+	Opponent      string
+	Turn          string
+	TurnDeadline  time.Time
+	Status        string
+	Winner        string
+	FinishReason  string
+	Clock         Clock
+	Shots         map[string]string
+	IncomingShots map[string]string
+	Ships         map[string][][]int
+	Rules         game.Ruleset
+
+	// EventSeq is the seq of the last event logged when the game was read.
+	// The rest of the state is read after it, so it is never older.
+	EventSeq int64
 }
 
 func (c *Client) GetMeta(ctx context.Context, gameID string) (GameMeta, error) {
@@ -29,6 +39,10 @@ func (c *Client) GetMeta(ctx context.Context, gameID string) (GameMeta, error) {
 }
 
 func (c *Client) GetState(ctx context.Context, gameID string, player string) (GameState, error) {
+	if player != playerOne && player != playerTwo {
+		return GameState{}, ErrInvalidPlayer
+	}
+
 	meta, err := c.GetMeta(ctx, gameID)
 	if err != nil {
 		return GameState{}, err
@@ -39,22 +53,30 @@ func (c *Client) GetState(ctx context.Context, gameID string, player string) (Ga
 		return GameState{}, err
 	}
 
-	shipsJSON, err := c.client.HGet(ctx, boardKey(gameID, player), "ships").Result()
-	if err != nil && !errors.Is(err, redis.Nil) {
+	incoming, err := c.client.HGetAll(ctx, shotsKey(gameID, opponent(player))).Result()
+	if err != nil {
 		return GameState{}, err
 	}
 
-	ships := map[string][][]int{}
-	if shipsJSON != "" {
-		_ = json.Unmarshal([]byte(shipsJSON), &ships)
+	ships, err := c.boardShips(ctx, gameID, player)
+	if err != nil {
+		return GameState{}, err
 	}
 
 	return GameState{
-		GameID: meta.ID,
-		Turn:   meta.Turn,
-		Status: meta.Status,
-		Winner: meta.Winner,
-		Shots:  shots,
-		Ships:  ships,
+		GameID:        meta.ID,
+		Player:        player,
+		Opponent:      meta.Opponent,
+		Turn:          meta.Turn,
+		TurnDeadline:  meta.TurnDeadline,
+		Status:        meta.Status,
+		Winner:        meta.Winner,
+		FinishReason:  meta.FinishReason,
+		Clock:         meta.Clock,
+		Shots:         shots,
+		IncomingShots: incoming,
+		Ships:         ships,
+		Rules:         meta.Rules,
+		EventSeq:      meta.EventSeq,
 	}, nil
 }
@@ -21,7 +21,7 @@ func TestGetStateEmpty(t *testing.T) {
 	client, cleanup := newTestClient(t)
 	defer cleanup()
 
-	meta, err := client.CreateGame(context.Background())
+	meta, err := client.CreateGame(context.Background(), GameOptions{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -45,7 +45,7 @@ func TestGetStateWithShips(t *testing.T) {
 	client, cleanup := newTestClient(t)
 	defer cleanup()
 
-	meta, err := client.CreateGame(context.Background())
+	meta, err := client.CreateGame(context.Background(), GameOptions{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -70,3 +70,42 @@ func TestGetStateWithShips(t *testing.T) {
 		t.Fatalf("expected 2 cells, got %d", len(cells))
 	}
 }
+
+func TestGetStateIncomingShots(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	if err := client.PlaceShips(ctx, meta.ID, "p1", ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, "p2", ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}); err != nil {
+		t.Fatalf("place p2: %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, "p1", game.Coord{Row: 5, Col: 5}); err != nil {
+		t.Fatalf("fire: %v", err)
+	}
+
+	state, err := client.GetState(ctx, meta.ID, "p2")
+	if err != nil {
+		t.Fatalf("get state: %v", err)
+	}
+	if state.IncomingShots["5,5"] != "hit" {
+		t.Fatalf("expected incoming hit at 5,5, got %v", state.IncomingShots)
+	}
+	if len(state.Shots) != 0 {
+		t.Fatalf("expected no own shots, got %v", state.Shots)
+	}
+	if _, ok := state.Ships["destroyer"]; !ok || len(state.Ships) != 1 {
+		t.Fatalf("expected only own ships, got %v", state.Ships)
+	}
+}
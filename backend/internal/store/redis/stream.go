@@ -0,0 +1,134 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamMaxLen    = 1000
+	streamBatchSize = 100
+	streamBlock     = time.Second
+	// streamIdleTTL is how long a relay stream, and an instance's offsets,
+	// outlive the last write to them.
+	streamIdleTTL = time.Hour
+)
+
+// Stream relays game messages through one Redis stream per game. Each
+// instance remembers how far it has read every stream under its own name, so
+// after a crash or restart it resumes where it stopped instead of losing what
+// was published in between. An offset is dropped when its subscription is
+// cancelled: a room that empties and is joined again later starts from the
+// end, as its clients get a fresh snapshot on connect. Streams are trimmed to
+// roughly the last streamMaxLen messages and expire after streamIdleTTL
+// without a message.
+type Stream struct {
+	client   *Client
+	instance string
+}
+
+func NewStream(client *Client, instance string) *Stream {
+	return &Stream{client: client, instance: instance}
+}
+
+func (s *Stream) Publish(ctx context.Context, gameID string, data []byte) error {
+	pipe := s.client.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: gameStreamKey(gameID),
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]any{"data": data},
+	})
+	pipe.Expire(ctx, gameStreamKey(gameID), streamIdleTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Subscribe delivers the messages of gameID after the stored offset of this
+// instance, or after the current end of the stream when it has never read it.
+// The offset advances as messages are handed over. The channel is closed and
+// the offset deleted once ctx is done.
+func (s *Stream) Subscribe(ctx context.Context, gameID string) (<-chan []byte, error) {
+	offset, err := s.offset(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan []byte, subscriptionBuffer)
+	go func() {
+		defer close(out)
+		defer s.client.client.HDel(context.Background(), s.offsetsKey(), gameID)
+
+		for ctx.Err() == nil {
+			streams, err := s.client.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{gameStreamKey(gameID), offset},
+				Count:   streamBatchSize,
+				Block:   streamBlock,
+			}).Result()
+			if err != nil {
+				if !errors.Is(err, redis.Nil) {
+					select {
+					case <-ctx.Done():
+					case <-time.After(streamBlock):
+					}
+				}
+				continue
+			}
+
+			for _, stream := range streams {
+				for _, msg := range stream.Messages {
+					if ctx.Err() != nil {
+						return
+					}
+					data, _ := msg.Values["data"].(string)
+					select {
+					case out <- []byte(data):
+					case <-ctx.Done():
+						return
+					}
+					offset = msg.ID
+				}
+			}
+			s.saveOffset(gameID, offset)
+		}
+	}()
+	return out, nil
+}
+
+func (s *Stream) offset(ctx context.Context, gameID string) (string, error) {
+	offset, err := s.client.client.HGet(ctx, s.offsetsKey(), gameID).Result()
+	if err == nil {
+		return offset, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	last, err := s.client.client.XRevRangeN(ctx, gameStreamKey(gameID), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(last) == 0 {
+		return "0-0", nil
+	}
+	return last[0].ID, nil
+}
+
+func (s *Stream) saveOffset(gameID string, offset string) {
+	pipe := s.client.client.TxPipeline()
+	pipe.HSet(context.Background(), s.offsetsKey(), gameID, offset)
+	pipe.Expire(context.Background(), s.offsetsKey(), streamIdleTTL)
+	_, _ = pipe.Exec(context.Background())
+}
+
+func (s *Stream) offsetsKey() string {
+	return fmt.Sprintf("relay:offsets:%s", s.instance)
+}
+
+func gameStreamKey(id string) string {
+	return fmt.Sprintf("game:%s:relay", id)
+}
@@ -0,0 +1,140 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func readRelayed(t *testing.T, messages <-chan []byte) string {
+	select {
+	case data := <-messages:
+		return string(data)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timeout waiting for message")
+	}
+	return ""
+}
+
+func TestStreamDeliversNewMessages(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	stream := NewStream(client, "instance-a")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := stream.Publish(ctx, "game-1", []byte("before")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+
+	messages, err := stream.Subscribe(ctx, "game-1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+	if err := stream.Publish(ctx, "game-2", []byte("other")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+	if err := stream.Publish(ctx, "game-1", []byte("after")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+
+	if got := readRelayed(t, messages); got != "after" {
+		t.Fatalf("expected only messages after subscribing, got %q", got)
+	}
+}
+
+func TestStreamResumesFromOffset(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	stream := NewStream(client, "instance-a")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	messages, err := stream.Subscribe(ctx, "game-1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+	if err := stream.Publish(context.Background(), "game-1", []byte("first")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+	if got := readRelayed(t, messages); got != "first" {
+		t.Fatalf("expected first, got %q", got)
+	}
+	var offset string
+	for deadline := time.Now().Add(time.Second); offset == "" && time.Now().Before(deadline); {
+		offset, _ = client.client.HGet(context.Background(), stream.offsetsKey(), "game-1").Result()
+	}
+	if offset == "" {
+		t.Fatalf("expected offset to be saved")
+	}
+	if ttl := client.client.TTL(context.Background(), stream.offsetsKey()).Val(); ttl <= 0 {
+		t.Fatalf("expected offsets to expire, got ttl %v", ttl)
+	}
+
+	// A cancelled subscription is a room that emptied; it drops its offset.
+	cancel()
+	for range messages {
+	}
+	if client.client.HExists(context.Background(), stream.offsetsKey(), "game-1").Val() {
+		t.Fatalf("expected offset to be deleted on unsubscribe")
+	}
+
+	for _, data := range []string{"missed-1", "missed-2"} {
+		if err := stream.Publish(context.Background(), "game-1", []byte(data)); err != nil {
+			t.Fatalf("publish error: %v", err)
+		}
+	}
+
+	// An instance that crashed never cancelled, so its offset is still there.
+	client.client.HSet(context.Background(), stream.offsetsKey(), "game-1", offset)
+	restarted := NewStream(client, "instance-a")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	messages, err = restarted.Subscribe(ctx, "game-1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+	for _, want := range []string{"missed-1", "missed-2"} {
+		if got := readRelayed(t, messages); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestStreamRejoinStartsFromEnd(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	stream := NewStream(client, "instance-a")
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, err := stream.Subscribe(ctx, "user:u1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+	if err := stream.Publish(context.Background(), "user:u1", []byte("old")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+	if got := readRelayed(t, messages); got != "old" {
+		t.Fatalf("expected old, got %q", got)
+	}
+	cancel()
+	for range messages {
+	}
+	if ttl := client.client.TTL(context.Background(), gameStreamKey("user:u1")).Val(); ttl <= 0 || ttl > streamIdleTTL {
+		t.Fatalf("expected stream to expire when idle, got ttl %v", ttl)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	messages, err = stream.Subscribe(ctx, "user:u1")
+	if err != nil {
+		t.Fatalf("subscribe error: %v", err)
+	}
+	if err := stream.Publish(context.Background(), "user:u1", []byte("new")); err != nil {
+		t.Fatalf("publish error: %v", err)
+	}
+	if got := readRelayed(t, messages); got != "new" {
+		t.Fatalf("expected only messages after rejoining, got %q", got)
+	}
+}
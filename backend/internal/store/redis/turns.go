@@ -0,0 +1,219 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"shipsgame/internal/game"
+)
+
+const (
+	TimeoutSkip       = "skip"
+	TimeoutRandomShot = "random_shot"
+)
+
+// turnDeadlinesKey indexes every running turn clock by its deadline (unix ms),
+// and AI games by when the bot's move is retried, so sweepers can find them
+// without scanning games.
+const turnDeadlinesKey = "games:turn_deadlines"
+
+var (
+	ErrInvalidTurnTimeout = errors.New("invalid turn timeout")
+	ErrTurnNotExpired     = errors.New("turn not expired")
+	// ErrBotTurn means the AI has not moved within its retry window, for
+	// example because its last attempt failed; the caller should play it.
+	ErrBotTurn = errors.New("bot turn pending")
+)
+
+type TurnExpiry struct {
+	GameID  string
+	Player  string
+	Skipped bool
+	Flagged bool
+	Coord   game.Coord
+	Result  ShotResult
+
+	// Seq is the seq of the last event the expiry logged.
+	Seq int64
+}
+
+func (c *Client) ExpiredTurns(ctx context.Context, now time.Time, limit int64) ([]string, error) {
+	return c.client.ZRangeByScore(ctx, turnDeadlinesKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: limit,
+	}).Result()
+}
+
+// ExpireTurn ends the current turn of gameID if its deadline has passed,
+// either skipping it or firing a random shot for the idle player depending on
+// the game's timeout action. When the idle player's time bank is empty the
+// game is finished instead and the expiry is Flagged. The check and the move
+// happen in one script, so when several sweepers race only one of them acts;
+// the others get ErrTurnNotExpired. An AI game still waiting on the bot's
+// move past its retry window returns ErrBotTurn to one caller, and is retried
+// again later.
+func (c *Client) ExpireTurn(ctx context.Context, gameID string) (TurnExpiry, error) {
+	res, err := expireTurnScript.Run(ctx, c.client, []string{
+		gameMetaKey(gameID),
+		turnDeadlinesKey,
+		shotsKey(gameID, playerOne),
+		shotsKey(gameID, playerTwo),
+		occupancyKey(gameID, playerOne),
+		occupancyKey(gameID, playerTwo),
+		shipsKey(gameID, playerOne),
+		shipsKey(gameID, playerTwo),
+		eventsKey(gameID),
+		archiveOutboxKey,
+	}, gameID, rand.Intn(1<<30)).Result()
+	if err != nil {
+		return TurnExpiry{}, err
+	}
+
+	resultStr, ok := res.(string)
+	if !ok {
+		return TurnExpiry{}, errUnexpectedResponse
+	}
+	switch resultStr {
+	case "ERR:not_expired", "ERR:no_turn_clock", "ERR:game_not_active":
+		return TurnExpiry{}, ErrTurnNotExpired
+	case "ERR:bot_turn":
+		return TurnExpiry{}, ErrBotTurn
+	case "ERR:game_not_found":
+		return TurnExpiry{}, ErrGameNotFound
+	}
+
+	parts := strings.SplitN(resultStr, "|", 4)
+	expiry := TurnExpiry{GameID: gameID}
+	switch {
+	case len(parts) == 3 && parts[0] == "skipped":
+		expiry.Player = parts[1]
+		expiry.Skipped = true
+		expiry.Seq, err = strconv.ParseInt(parts[2], 10, 64)
+	case len(parts) == 3 && parts[0] == "flagged":
+		expiry.Player = parts[1]
+		expiry.Flagged = true
+		expiry.Seq, err = strconv.ParseInt(parts[2], 10, 64)
+	case len(parts) == 4 && parts[0] == "shot":
+		expiry.Player = parts[1]
+		expiry.Coord, err = parseCoordKey(parts[2])
+		if err != nil {
+			return TurnExpiry{}, err
+		}
+		expiry.Result, err = parseShot(parts[3])
+		expiry.Seq = expiry.Result.Seq
+	default:
+		return TurnExpiry{}, errUnexpectedResponse
+	}
+	if err != nil {
+		return TurnExpiry{}, errUnexpectedResponse
+	}
+	return expiry, nil
+}
+
+func parseCoordKey(value string) (game.Coord, error) {
+	row, col, ok := strings.Cut(value, ",")
+	if !ok {
+		return game.Coord{}, errors.New("invalid coordinate")
+	}
+	var coord game.Coord
+	var err error
+	if coord.Row, err = strconv.Atoi(row); err != nil {
+		return game.Coord{}, err
+	}
+	if coord.Col, err = strconv.Atoi(col); err != nil {
+		return game.Coord{}, err
+	}
+	return coord, nil
+}
+
+func msToTime(value string) time.Time {
+	ms := atoi(value)
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ms))
+}
+
+var expireTurnScript = redis.NewScript(luaRules + luaEvents + luaTurns + `
+local meta = KEYS[1]
+local deadlines = KEYS[2]
+local shots = {p1 = KEYS[3], p2 = KEYS[4]}
+local occupancy = {p1 = KEYS[5], p2 = KEYS[6]}
+local ships = {p1 = KEYS[7], p2 = KEYS[8]}
+local events = KEYS[9]
+local outbox = KEYS[10]
+
+local game_id = ARGV[1]
+local pick = tonumber(ARGV[2])
+
+if redis.call('EXISTS', meta) == 0 then
+  redis.call('ZREM', deadlines, game_id)
+  return 'ERR:game_not_found'
+end
+
+if redis.call('HGET', meta, 'status') ~= 'active' then
+  redis.call('ZREM', deadlines, game_id)
+  return 'ERR:game_not_active'
+end
+
+local now = now_ms()
+local player = redis.call('HGET', meta, 'turn')
+local other = (player == 'p1') and 'p2' or 'p1'
+
+if clock_enabled(meta) and clock_remaining(meta, player, now) <= 0 then
+  redis.call('HSET', meta, player .. '_clock_ms', 0)
+  local seq = finish_game(meta, events, other, 'timeout', deadlines, outbox)
+  return 'flagged|' .. player .. '|' .. seq
+end
+
+local deadline = tonumber(redis.call('HGET', meta, 'turn_deadline')) or 0
+if bot_turn(meta) and (deadline <= 0 or deadline > now) then
+  if (tonumber(redis.call('ZSCORE', deadlines, game_id)) or 0) > now then
+    return 'ERR:not_expired'
+  end
+  schedule_turn(meta, deadlines, now)
+  return 'ERR:bot_turn'
+end
+if deadline <= 0 then
+  if clock_enabled(meta) then
+    return 'ERR:not_expired'
+  end
+  redis.call('ZREM', deadlines, game_id)
+  return 'ERR:no_turn_clock'
+end
+if deadline > now then
+  return 'ERR:not_expired'
+end
+
+charge_clock(meta, player, now, 0)
+
+if redis.call('HGET', meta, 'timeout_action') == 'random_shot' then
+  local rules = load_rules(meta)
+  local open = {}
+  for row = 0, tonumber(rules['height']) - 1 do
+    for col = 0, tonumber(rules['width']) - 1 do
+      local coord = row .. ',' .. col
+      if redis.call('HEXISTS', shots[player], coord) == 0 then
+        table.insert(open, coord)
+      end
+    end
+  end
+  if #open > 0 then
+    local coord = open[(pick % #open) + 1]
+    local outcome, seq = apply_shot(meta, events, player, coord, shots[player], occupancy[other], ships[other])
+    end_turn(meta, events, player, deadlines, outbox)
+    return 'shot|' .. player .. '|' .. coord .. '|' .. outcome .. '|' .. seq
+  end
+end
+
+redis.call('HSET', meta, 'turn', other)
+start_turn_clock(meta, deadlines)
+local seq = append_event(meta, events, {type = 'turn_skipped', player = player})
+return 'skipped|' .. player .. '|' .. seq
+`)
@@ -0,0 +1,236 @@
+package redisstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"shipsgame/internal/game"
+)
+
+func startTimedGame(t *testing.T, client *Client, action string) GameMeta {
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{TurnTimeout: 30 * time.Second, TimeoutAction: action})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+	return meta
+}
+
+func expireDeadline(t *testing.T, client *Client, gameID string) {
+	ctx := context.Background()
+	if err := client.client.HSet(ctx, gameMetaKey(gameID), "turn_deadline", 1).Err(); err != nil {
+		t.Fatalf("set deadline: %v", err)
+	}
+	if err := client.client.ZAdd(ctx, turnDeadlinesKey, redis.Z{Score: 1, Member: gameID}).Err(); err != nil {
+		t.Fatalf("set deadline index: %v", err)
+	}
+}
+
+func TestTurnClockStartsAndRestarts(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+
+	stored, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.TurnTimeout != 30*time.Second {
+		t.Fatalf("expected 30s timeout, got %s", stored.TurnTimeout)
+	}
+	if stored.TurnDeadline.Before(time.Now().Add(20 * time.Second)) {
+		t.Fatalf("expected deadline about 30s away, got %s", stored.TurnDeadline)
+	}
+
+	if _, err := client.ExpireTurn(ctx, meta.ID); err != ErrTurnNotExpired {
+		t.Fatalf("expected not expired, got %v", err)
+	}
+	ids, err := client.ExpiredTurns(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("expired turns error: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("expected no expired turns, got %v", ids)
+	}
+
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 5, Col: 5}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, playerTwo, game.Coord{Row: 0, Col: 0}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 5, Col: 6}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+
+	stored, err = client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.Status != "finished" || !stored.TurnDeadline.IsZero() {
+		t.Fatalf("expected finished game without clock, got status=%s deadline=%s", stored.Status, stored.TurnDeadline)
+	}
+	count, err := client.client.ZCard(ctx, turnDeadlinesKey).Result()
+	if err != nil {
+		t.Fatalf("zcard error: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected finished game removed from deadline index")
+	}
+}
+
+func TestExpireTurnSkipsOnce(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutSkip)
+	expireDeadline(t, client, meta.ID)
+
+	ids, err := client.ExpiredTurns(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("expired turns error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != meta.ID {
+		t.Fatalf("expected expired game %s, got %v", meta.ID, ids)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	expired := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			expiry, err := client.ExpireTurn(ctx, meta.ID)
+			if err == ErrTurnNotExpired {
+				return
+			}
+			if err != nil {
+				t.Errorf("expire turn error: %v", err)
+				return
+			}
+			if !expiry.Skipped || expiry.Player != playerOne {
+				t.Errorf("expected p1 turn skipped, got %+v", expiry)
+			}
+			mu.Lock()
+			expired++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if expired != 1 {
+		t.Fatalf("expected exactly one sweeper to expire the turn, got %d", expired)
+	}
+
+	stored, err := client.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta error: %v", err)
+	}
+	if stored.Turn != playerTwo {
+		t.Fatalf("expected turn passed to p2, got %s", stored.Turn)
+	}
+	if !stored.TurnDeadline.After(time.Now()) {
+		t.Fatalf("expected a fresh deadline for p2")
+	}
+}
+
+func TestExpireTurnRandomShot(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTimedGame(t, client, TimeoutRandomShot)
+	expireDeadline(t, client, meta.ID)
+
+	expiry, err := client.ExpireTurn(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("expire turn error: %v", err)
+	}
+	if expiry.Skipped || expiry.Player != playerOne {
+		t.Fatalf("expected random shot for p1, got %+v", expiry)
+	}
+
+	state, err := client.GetState(ctx, meta.ID, playerOne)
+	if err != nil {
+		t.Fatalf("get state error: %v", err)
+	}
+	if _, ok := state.Shots[coordKey(expiry.Coord)]; !ok || len(state.Shots) != 1 {
+		t.Fatalf("expected recorded shot at %v, got %v", expiry.Coord, state.Shots)
+	}
+	if state.Turn != playerTwo {
+		t.Fatalf("expected turn passed to p2, got %s", state.Turn)
+	}
+	if expiry.Seq == 0 || expiry.Seq != state.EventSeq {
+		t.Fatalf("expected the expiry to report the last seq %d, got %d", state.EventSeq, expiry.Seq)
+	}
+}
+
+func TestExpireTurnRetriesStalledBot(t *testing.T) {
+	client, cleanup := newTestClient(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := client.CreateGame(ctx, GameOptions{Opponent: OpponentAI})
+	if err != nil {
+		t.Fatalf("create game error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerOne, ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place ships p1 error: %v", err)
+	}
+	if err := client.PlaceShips(ctx, meta.ID, playerTwo, ShipsPlacement{
+		game.Destroyer: {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	}); err != nil {
+		t.Fatalf("place ships p2 error: %v", err)
+	}
+
+	if _, err := client.client.ZScore(ctx, turnDeadlinesKey, meta.ID).Result(); err != redis.Nil {
+		t.Fatalf("expected no wake on p1's untimed turn, got %v", err)
+	}
+	if _, err := client.Fire(ctx, meta.ID, playerOne, game.Coord{Row: 9, Col: 9}); err != nil {
+		t.Fatalf("fire error: %v", err)
+	}
+
+	retry, err := client.client.ZScore(ctx, turnDeadlinesKey, meta.ID).Result()
+	if err != nil {
+		t.Fatalf("expected a bot retry wake: %v", err)
+	}
+	if retry < float64(time.Now().Add(4*time.Second).UnixMilli()) {
+		t.Fatalf("expected the retry about five seconds away, got %v", retry)
+	}
+	if _, err := client.ExpireTurn(ctx, meta.ID); err != ErrTurnNotExpired {
+		t.Fatalf("expected the bot to have time to move, got %v", err)
+	}
+
+	expireDeadline(t, client, meta.ID)
+	if err := client.client.HSet(ctx, gameMetaKey(meta.ID), "turn_deadline", 0).Err(); err != nil {
+		t.Fatalf("clear deadline: %v", err)
+	}
+	if _, err := client.ExpireTurn(ctx, meta.ID); err != ErrBotTurn {
+		t.Fatalf("expected a stalled bot turn, got %v", err)
+	}
+	if _, err := client.ExpireTurn(ctx, meta.ID); err != ErrTurnNotExpired {
+		t.Fatalf("expected the retry to be handed out once, got %v", err)
+	}
+	if retry, err := client.client.ZScore(ctx, turnDeadlinesKey, meta.ID).Result(); err != nil || retry < float64(time.Now().UnixMilli()) {
+		t.Fatalf("expected the retry rescheduled, got %v %v", retry, err)
+	}
+}
@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"context"
+	"sync"
+)
+
+const memoryBuffer = 64
+
+// MemoryBroadcaster relays messages between hubs in the same process. It is
+// enough for a single instance and lets tests run several hubs without Redis.
+// Slow subscribers drop messages rather than block publishers, like clients
+// in the Hub.
+type MemoryBroadcaster struct {
+	mu   sync.Mutex
+	subs map[string]map[chan []byte]bool
+}
+
+func NewMemoryBroadcaster() *MemoryBroadcaster {
+	return &MemoryBroadcaster{subs: make(map[string]map[chan []byte]bool)}
+}
+
+func (b *MemoryBroadcaster) Publish(_ context.Context, gameID string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[gameID] {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroadcaster) Subscribe(ctx context.Context, gameID string) (<-chan []byte, error) {
+	ch := make(chan []byte, memoryBuffer)
+
+	b.mu.Lock()
+	if b.subs[gameID] == nil {
+		b.subs[gameID] = make(map[chan []byte]bool)
+	}
+	b.subs[gameID][ch] = true
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs[gameID], ch)
+		if len(b.subs[gameID]) == 0 {
+			delete(b.subs, gameID)
+		}
+		close(ch)
+		b.mu.Unlock()
+	}()
+	return ch, nil
+}
@@ -0,0 +1,176 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const (
+	maxChatLength    = 200
+	chatHistoryLimit = 50
+
+	// Each client may send chatBurst messages at once and one more every
+	// chatRefill after that. Emotes count too.
+	chatBurst  = 5
+	chatRefill = 2 * time.Second
+)
+
+// emotes are the quick reactions a client may send; anything else is
+// rejected.
+var emotes = []string{"gg", "nice_shot", "oops", "thinking", "wave", "well_played"}
+
+// ChatFilter checks a chat message before it is sent. It returns the text to
+// send, possibly masked, or false to drop the message.
+type ChatFilter func(gameID, player, text string) (string, bool)
+
+// NewBlocklistFilter masks every listed word, ignoring case, with asterisks.
+func NewBlocklistFilter(words []string) ChatFilter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+	return func(_, _, text string) (string, bool) {
+		return pattern.ReplaceAllStringFunc(text, func(match string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(match))
+		}), true
+	}
+}
+
+// chatLimiter is a token bucket per client. It is only used from the
+// client's read loop, so it needs no lock.
+type chatLimiter struct {
+	tokens float64
+	last   time.Time
+}
+
+func (l *chatLimiter) allow(now time.Time) bool {
+	if l.last.IsZero() {
+		l.tokens = chatBurst
+	} else {
+		l.tokens = min(chatBurst, l.tokens+float64(now.Sub(l.last))/float64(chatRefill))
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+func (s *Server) handleChat(client *Client, payload json.RawMessage) {
+	var chat ChatPayload
+	if err := json.Unmarshal(payload, &chat); err != nil {
+		s.sendError(client, "invalid chat payload")
+		return
+	}
+	if chat.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	text := strings.TrimSpace(chat.Text)
+	if text == "" {
+		s.sendError(client, "empty chat message")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxChatLength {
+		s.sendError(client, "chat message too long")
+		return
+	}
+	if !client.chat.allow(time.Now()) {
+		s.sendError(client, "chat rate limit exceeded")
+		return
+	}
+	if s.ChatFilter != nil {
+		var ok bool
+		if text, ok = s.ChatFilter(chat.GameID, client.Player, text); !ok {
+			s.sendError(client, "chat message rejected")
+			return
+		}
+	}
+
+	s.sendChat(client, redisstore.ChatEntry{Kind: redisstore.ChatKindText, Player: client.Player, Text: text})
+}
+
+func (s *Server) handleEmote(client *Client, payload json.RawMessage) {
+	var emote EmotePayload
+	if err := json.Unmarshal(payload, &emote); err != nil {
+		s.sendError(client, "invalid emote payload")
+		return
+	}
+	if emote.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	known := false
+	for _, name := range emotes {
+		known = known || name == emote.Emote
+	}
+	if !known {
+		s.sendError(client, "unknown emote")
+		return
+	}
+	if !client.chat.allow(time.Now()) {
+		s.sendError(client, "chat rate limit exceeded")
+		return
+	}
+
+	s.sendChat(client, redisstore.ChatEntry{Kind: redisstore.ChatKindEmote, Player: client.Player, Emote: emote.Emote})
+}
+
+// sendChat keeps entry in the game's history and broadcasts it to the room on
+// every instance.
+func (s *Server) sendChat(client *Client, entry redisstore.ChatEntry) {
+	entry.At = time.Now().UnixMilli()
+	seq, err := s.Store.AppendChat(context.Background(), client.GameID, entry, chatHistoryLimit)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("chat failed game_id=%s player=%s err=%v", client.GameID, client.Player, err)
+		}
+		s.sendError(client, "failed to send chat message")
+		return
+	}
+
+	msg := ServerMessage{Type: entry.Kind, Seq: seq, Payload: chatPayload(client.GameID, entry)}
+	if data, err := s.encode(client.GameID, msg); err == nil {
+		s.Hub.Broadcast(client.GameID, data)
+	}
+}
+
+// chatHistory is the recent chat sent with the initial state. A failure only
+// costs the history, not the state.
+func (s *Server) chatHistory(gameID string) []ChatMessagePayload {
+	entries, err := s.Store.ChatHistory(context.Background(), gameID)
+	if err != nil {
+		return nil
+	}
+	history := make([]ChatMessagePayload, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, chatPayload(gameID, entry))
+	}
+	return history
+}
+
+func chatPayload(gameID string, entry redisstore.ChatEntry) ChatMessagePayload {
+	return ChatMessagePayload{
+		GameID: gameID,
+		Kind:   entry.Kind,
+		Player: entry.Player,
+		Text:   entry.Text,
+		Emote:  entry.Emote,
+		At:     entry.At,
+	}
+}
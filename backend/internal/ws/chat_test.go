@@ -0,0 +1,135 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func sendClientMessage(wsServer *Server, client *Client, msgType string, payload any) {
+	body, _ := json.Marshal(payload)
+	data, _ := json.Marshal(ClientMessage{Type: msgType, Payload: body})
+	wsServer.handleMessage(client, data)
+}
+
+func readChat(t *testing.T, ch <-chan []byte) ChatMessagePayload {
+	msg := readMessage(t, ch)
+	if msg.Type != "chat" && msg.Type != "emote" {
+		t.Fatalf("expected chat or emote, got %s", msg.Type)
+	}
+	var chat ChatMessagePayload
+	if err := json.Unmarshal(msg.Payload, &chat); err != nil {
+		t.Fatalf("unmarshal chat: %v", err)
+	}
+	return chat
+}
+
+func TestChatBroadcastsAndIsKeptForReconnects(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+	wsServer.ChatFilter = NewBlocklistFilter([]string{"darn"})
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+	p2 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+	watcher := &Client{Hub: wsServer.Hub, GameID: meta.ID, Role: auth.RoleSpectator, Send: make(chan []byte, 4)}
+	for _, client := range []*Client{p1, p2, watcher} {
+		registerClient(wsServer.Hub, client)
+	}
+
+	sendClientMessage(wsServer, p1, "chat", ChatPayload{GameID: meta.ID, Text: "  Darn, missed  "})
+	for _, client := range []*Client{p1, p2, watcher} {
+		if chat := readChat(t, client.Send); chat.Player != "p1" || chat.Text != "****, missed" || chat.At == 0 {
+			t.Fatalf("unexpected chat %+v", chat)
+		}
+	}
+	sendClientMessage(wsServer, p2, "emote", EmotePayload{GameID: meta.ID, Emote: "gg"})
+	for _, client := range []*Client{p1, p2, watcher} {
+		if chat := readChat(t, client.Send); chat.Kind != "emote" || chat.Emote != "gg" || chat.Player != "p2" {
+			t.Fatalf("unexpected emote %+v", chat)
+		}
+	}
+
+	sendClientMessage(wsServer, watcher, "chat", ChatPayload{GameID: meta.ID, Text: "hi"})
+	if msg := readMessage(t, watcher.Send); msg.Type != "error" {
+		t.Fatalf("expected spectators to be read-only, got %s", msg.Type)
+	}
+	for _, bad := range []struct {
+		msgType string
+		payload any
+	}{
+		{"chat", ChatPayload{GameID: meta.ID, Text: strings.Repeat("a", maxChatLength+1)}},
+		{"chat", ChatPayload{GameID: meta.ID, Text: "   "}},
+		{"chat", ChatPayload{GameID: "other", Text: "hi"}},
+		{"emote", EmotePayload{GameID: meta.ID, Emote: "rude"}},
+	} {
+		sendClientMessage(wsServer, p1, bad.msgType, bad.payload)
+		if msg := readMessage(t, p1.Send); msg.Type != "error" {
+			t.Fatalf("expected error for %+v, got %s", bad.payload, msg.Type)
+		}
+	}
+	expectNoMessage(t, p2.Send)
+
+	reconnected := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+	wsServer.SendInitialState(reconnected)
+	state := readStatePayload(t, reconnected.Send)
+	if len(state.Chat) != 2 || state.Chat[0].Text != "****, missed" || state.Chat[1].Emote != "gg" {
+		t.Fatalf("expected chat history in initial state, got %+v", state.Chat)
+	}
+}
+
+func TestChatIsRateLimited(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 16)}
+	registerClient(wsServer.Hub, p1)
+
+	for i := 0; i < chatBurst; i++ {
+		sendClientMessage(wsServer, p1, "emote", EmotePayload{GameID: meta.ID, Emote: "wave"})
+		if msg := readMessage(t, p1.Send); msg.Type != "emote" {
+			t.Fatalf("expected emote %d through, got %s", i, msg.Type)
+		}
+	}
+	sendClientMessage(wsServer, p1, "chat", ChatPayload{GameID: meta.ID, Text: "one more"})
+	if msg := readMessage(t, p1.Send); msg.Type != "error" {
+		t.Fatalf("expected rate limit error, got %s", msg.Type)
+	}
+
+	p1.chat.last = p1.chat.last.Add(-chatRefill)
+	sendClientMessage(wsServer, p1, "chat", ChatPayload{GameID: meta.ID, Text: "one more"})
+	if msg := readMessage(t, p1.Send); msg.Type != "chat" {
+		t.Fatalf("expected chat after refill, got %s", msg.Type)
+	}
+}
+
+func TestChatLimiterRefills(t *testing.T) {
+	var limiter chatLimiter
+	now := time.Now()
+	for i := 0; i < chatBurst; i++ {
+		if !limiter.allow(now) {
+			t.Fatalf("expected message %d allowed", i)
+		}
+	}
+	if limiter.allow(now) {
+		t.Fatalf("expected burst exhausted")
+	}
+	if !limiter.allow(now.Add(chatRefill)) {
+		t.Fatalf("expected one message after refill")
+	}
+	if limiter.allow(now.Add(chatRefill)) {
+		t.Fatalf("expected only one message after refill")
+	}
+}
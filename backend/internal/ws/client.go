@@ -18,7 +18,19 @@ type Client struct {
 	Conn   *websocket.Conn
 	GameID string
 	Player string
-	Send   chan []byte
+	// Role is auth.RolePlayer or auth.RoleSpectator in game rooms and empty
+	// in user rooms. Spectators have no Player.
+	Role string
+	// ID tells apart connections of the same player.
+	ID string
+	// LastSeq is the last_seq the client reconnected with, 0 on a fresh
+	// connection.
+	LastSeq int64
+	Send    chan []byte
+
+	chat    chatLimiter
+	joined  chan struct{}
+	request *clientRequest
 }
 
 func (c *Client) readPump(onMessage func(*Client, []byte)) {
@@ -31,6 +43,7 @@ func (c *Client) readPump(onMessage func(*Client, []byte)) {
 	_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
 		_ = c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.Hub.heartbeat(c)
 		return nil
 	})
 
@@ -1,16 +1,28 @@
 package ws
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/websocket"
+	"shipsgame/internal/auth"
 )
 
+// Identity is what a connection was authenticated as: the room it joins and,
+// in a game room, its seat and role.
+type Identity struct {
+	GameID string
+	Player string
+	Role   string
+}
+
 type Handler struct {
 	Hub       *Hub
 	Upgrader  websocket.Upgrader
 	OnMessage func(*Client, []byte)
-	Auth      func(*http.Request) (string, string, error)
+	Auth      func(*http.Request) (Identity, error)
 	OnConnect func(*Client)
 }
 
@@ -28,19 +40,21 @@ func NewHandler(hub *Hub) *Handler {
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	gameID := ""
-	player := ""
+	var identity Identity
 	if h.Auth != nil {
 		var err error
-		gameID, player, err = h.Auth(r)
+		identity, err = h.Auth(r)
 		if err != nil {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
 	} else {
-		gameID = r.URL.Query().Get("game_id")
-		player = r.URL.Query().Get("player")
-		if gameID == "" || player == "" {
+		identity = Identity{
+			GameID: r.URL.Query().Get("game_id"),
+			Player: r.URL.Query().Get("player"),
+			Role:   auth.RolePlayer,
+		}
+		if identity.GameID == "" || identity.Player == "" {
 			http.Error(w, "missing game_id or player", http.StatusBadRequest)
 			return
 		}
@@ -51,13 +65,20 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
 	client := &Client{
 		Hub:    h.Hub,
 		Conn:   conn,
-		GameID: gameID,
-		Player: player,
+		ID:     hex.EncodeToString(id),
+		GameID: identity.GameID,
+		Player: identity.Player,
+		Role:   identity.Role,
 		Send:   make(chan []byte, 256),
 	}
+	if lastSeq, err := strconv.ParseInt(r.URL.Query().Get("last_seq"), 10, 64); err == nil && lastSeq > 0 {
+		client.LastSeq = lastSeq
+	}
 
 	h.Hub.register <- client
 	if h.OnConnect != nil {
@@ -1,27 +1,80 @@
 package ws
 
-import "sync"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
 
+	"shipsgame/internal/auth"
+)
+
+const resubscribeDelay = time.Second
+
+// Broadcaster carries hub messages between backend instances. Subscribe
+// delivers everything published for gameID until ctx is done, then closes the
+// channel.
+type Broadcaster interface {
+	Publish(ctx context.Context, gameID string, data []byte) error
+	Subscribe(ctx context.Context, gameID string) (<-chan []byte, error)
+}
+
+// HubMessage goes to every client in the GameID room, or only to those with
+// the given Role and Player when set.
 type HubMessage struct {
 	GameID string
+	Role   string
+	Player string
 	Data   []byte
 }
 
+// relayEnvelope is what a hub publishes for other instances. Origin lets a hub
+// skip its own messages, which it already delivered locally.
+type relayEnvelope struct {
+	Origin string          `json:"origin"`
+	Role   string          `json:"role,omitempty"`
+	Player string          `json:"player,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// PresenceHook hears about clients joining and leaving rooms and about their
+// heartbeats. The hub calls Joined and Left outside its loop, so they may
+// broadcast; Left is only called once Joined has returned.
+type PresenceHook interface {
+	Joined(client *Client)
+	Heartbeat(client *Client)
+	Left(client *Client)
+}
+
 type Hub struct {
+	// Broadcaster, when set before Run, relays messages to clients connected
+	// to other instances.
+	Broadcaster Broadcaster
+	// Presence, when set before Run, is told about every client.
+	Presence PresenceHook
+
+	id         string
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan HubMessage
 
-	mu    sync.RWMutex
-	rooms map[string]map[*Client]bool
+	mu      sync.RWMutex
+	rooms   map[string]map[*Client]bool
+	follows map[string]context.CancelFunc
 }
 
 func NewHub() *Hub {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
 	return &Hub{
+		id:         hex.EncodeToString(id),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan HubMessage),
 		rooms:      make(map[string]map[*Client]bool),
+		follows:    make(map[string]context.CancelFunc),
 	}
 }
 
@@ -34,9 +87,11 @@ func (h *Hub) Run() {
 			if room == nil {
 				room = make(map[*Client]bool)
 				h.rooms[client.GameID] = room
+				h.follow(client.GameID)
 			}
 			room[client] = true
 			h.mu.Unlock()
+			h.joined(client)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
@@ -45,9 +100,11 @@ func (h *Hub) Run() {
 				if _, ok := room[client]; ok {
 					delete(room, client)
 					close(client.Send)
+					h.left(client)
 				}
 				if len(room) == 0 {
 					delete(h.rooms, client.GameID)
+					h.unfollow(client.GameID)
 				}
 			}
 			h.mu.Unlock()
@@ -56,6 +113,9 @@ func (h *Hub) Run() {
 			h.mu.RLock()
 			room := h.rooms[msg.GameID]
 			for client := range room {
+				if !msg.reaches(client) {
+					continue
+				}
 				select {
 				case client.Send <- msg.Data:
 				default:
@@ -66,6 +126,126 @@ func (h *Hub) Run() {
 	}
 }
 
+func (h *Hub) joined(client *Client) {
+	done := make(chan struct{})
+	client.joined = done
+	if h.Presence == nil {
+		close(done)
+		return
+	}
+	go func() {
+		defer close(done)
+		h.Presence.Joined(client)
+	}()
+}
+
+func (h *Hub) left(client *Client) {
+	if h.Presence == nil {
+		return
+	}
+	done := client.joined
+	go func() {
+		<-done
+		h.Presence.Left(client)
+	}()
+}
+
+func (h *Hub) heartbeat(client *Client) {
+	if h.Presence != nil {
+		h.Presence.Heartbeat(client)
+	}
+}
+
+// reaches reports whether msg is meant for client. Messages for players never
+// reach spectators, and spectator messages only reach spectators.
+func (msg HubMessage) reaches(client *Client) bool {
+	spectator := client.Role == auth.RoleSpectator
+	switch msg.Role {
+	case auth.RolePlayer:
+		if spectator {
+			return false
+		}
+	case auth.RoleSpectator:
+		if !spectator {
+			return false
+		}
+	}
+	return msg.Player == "" || client.Player == msg.Player
+}
+
 func (h *Hub) Broadcast(gameID string, data []byte) {
-	h.broadcast <- HubMessage{GameID: gameID, Data: data}
+	msg := HubMessage{GameID: gameID, Data: data}
+	h.broadcast <- msg
+	h.relay(msg)
+}
+
+// SendToPlayer reaches only the connections seated as player, never
+// spectators.
+func (h *Hub) SendToPlayer(gameID string, player string, data []byte) {
+	msg := HubMessage{GameID: gameID, Role: auth.RolePlayer, Player: player, Data: data}
+	h.broadcast <- msg
+	h.relay(msg)
+}
+
+func (h *Hub) SendToSpectators(gameID string, data []byte) {
+	msg := HubMessage{GameID: gameID, Role: auth.RoleSpectator, Data: data}
+	h.broadcast <- msg
+	h.relay(msg)
+}
+
+func (h *Hub) relay(msg HubMessage) {
+	if h.Broadcaster == nil {
+		return
+	}
+	data, err := json.Marshal(relayEnvelope{Origin: h.id, Role: msg.Role, Player: msg.Player, Data: msg.Data})
+	if err != nil {
+		return
+	}
+	_ = h.Broadcaster.Publish(context.Background(), msg.GameID, data)
+}
+
+// follow starts relaying messages from other instances into a room that just
+// got its first local client. Callers hold h.mu.
+func (h *Hub) follow(gameID string) {
+	if h.Broadcaster == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.follows[gameID] = cancel
+	go h.receive(ctx, gameID)
+}
+
+func (h *Hub) unfollow(gameID string) {
+	if cancel, ok := h.follows[gameID]; ok {
+		cancel()
+		delete(h.follows, gameID)
+	}
+}
+
+func (h *Hub) receive(ctx context.Context, gameID string) {
+	for ctx.Err() == nil {
+		messages, err := h.Broadcaster.Subscribe(ctx, gameID)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(resubscribeDelay):
+			}
+			continue
+		}
+
+		for data := range messages {
+			if ctx.Err() != nil {
+				return
+			}
+			var envelope relayEnvelope
+			if err := json.Unmarshal(data, &envelope); err != nil || envelope.Origin == h.id {
+				continue
+			}
+			select {
+			case h.broadcast <- HubMessage{GameID: gameID, Role: envelope.Role, Player: envelope.Player, Data: envelope.Data}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
 }
@@ -0,0 +1,108 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func newRelayedHubs(t *testing.T, broadcaster Broadcaster) (*Hub, *Hub) {
+	hubs := make([]*Hub, 2)
+	for i := range hubs {
+		hubs[i] = NewHub()
+		hubs[i].Broadcaster = broadcaster
+		go hubs[i].Run()
+	}
+	return hubs[0], hubs[1]
+}
+
+// broadcasters returns every Broadcaster implementation; hub tests run
+// against each of them.
+func broadcasters(t *testing.T) map[string]Broadcaster {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	t.Cleanup(func() { _ = store.Close() })
+
+	return map[string]Broadcaster{
+		"memory": NewMemoryBroadcaster(),
+		"pubsub": redisstore.NewPubSub(store),
+		"stream": redisstore.NewStream(store, "test"),
+	}
+}
+
+func expectNoMessage(t *testing.T, ch <-chan []byte) {
+	select {
+	case data := <-ch:
+		t.Fatalf("unexpected message: %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHubRelaysBroadcastAcrossInstances(t *testing.T) {
+	for name, broadcaster := range broadcasters(t) {
+		t.Run(name, func(t *testing.T) {
+			first, second := newRelayedHubs(t, broadcaster)
+
+			p1 := &Client{Hub: first, GameID: "game", Player: "p1", Send: make(chan []byte, 4)}
+			p2 := &Client{Hub: second, GameID: "game", Player: "p2", Send: make(chan []byte, 4)}
+			other := &Client{Hub: second, GameID: "other", Player: "p1", Send: make(chan []byte, 4)}
+			registerClient(first, p1)
+			registerClient(second, p2)
+			registerClient(second, other)
+
+			first.Broadcast("game", []byte(`{"type":"shot_result"}`))
+
+			for _, client := range []*Client{p1, p2} {
+				if msg := readMessage(t, client.Send); msg.Type != "shot_result" {
+					t.Fatalf("expected shot_result for %s, got %s", client.Player, msg.Type)
+				}
+			}
+			expectNoMessage(t, p1.Send)
+			expectNoMessage(t, other.Send)
+		})
+	}
+}
+
+func TestHubRelaysPlayerMessagesAcrossInstances(t *testing.T) {
+	for name, broadcaster := range broadcasters(t) {
+		t.Run(name, func(t *testing.T) {
+			first, second := newRelayedHubs(t, broadcaster)
+
+			p1 := &Client{Hub: first, GameID: "game", Player: "p1", Send: make(chan []byte, 4)}
+			p2 := &Client{Hub: second, GameID: "game", Player: "p2", Send: make(chan []byte, 4)}
+			registerClient(first, p1)
+			registerClient(second, p2)
+
+			first.SendToPlayer("game", "p2", []byte(`{"type":"game_state"}`))
+
+			if msg := readMessage(t, p2.Send); msg.Type != "game_state" {
+				t.Fatalf("expected game_state, got %s", msg.Type)
+			}
+			expectNoMessage(t, p1.Send)
+		})
+	}
+}
+
+func TestHubStopsRelayWhenRoomEmpties(t *testing.T) {
+	for name, broadcaster := range broadcasters(t) {
+		t.Run(name, func(t *testing.T) {
+			first, second := newRelayedHubs(t, broadcaster)
+
+			p2 := &Client{Hub: second, GameID: "game", Player: "p2", Send: make(chan []byte, 4)}
+			registerClient(second, p2)
+			second.unregister <- p2
+			time.Sleep(10 * time.Millisecond)
+
+			rejoined := &Client{Hub: second, GameID: "game", Player: "p2", Send: make(chan []byte, 4)}
+			registerClient(second, rejoined)
+
+			first.Broadcast("game", []byte(`{"type":"turn_changed"}`))
+			if msg := readMessage(t, rejoined.Send); msg.Type != "turn_changed" {
+				t.Fatalf("expected turn_changed, got %s", msg.Type)
+			}
+			expectNoMessage(t, rejoined.Send)
+		})
+	}
+}
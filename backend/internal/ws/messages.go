@@ -2,14 +2,23 @@ package ws
 
 import "encoding/json"
 
+// ClientMessage.RequestID is optional. When set it is echoed in the replies
+// to that message, and a fire or place_ships sent again with the same ID gets
+// the original reply instead of being applied twice.
 type ClientMessage struct {
-	Type    string          `json:"type"`
-	Payload json.RawMessage `json:"payload"`
+	Type      string          `json:"type"`
+	RequestID string          `json:"request_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
 }
 
+// ServerMessage.Seq is the seq of the last event in the game's log when the
+// message was sent; it never decreases within a game. Messages outside a game
+// room carry none.
 type ServerMessage struct {
-	Type    string      `json:"type"`
-	Payload interface{} `json:"payload"`
+	Type      string      `json:"type"`
+	Seq       int64       `json:"seq,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Payload   interface{} `json:"payload"`
 }
 
 type PlaceShipsPayload struct {
@@ -22,40 +31,217 @@ type ShipPayload struct {
 	Cells []CoordPayload `json:"cells"`
 }
 
+type AutoPlacePayload struct {
+	GameID     string        `json:"game_id"`
+	Keep       []ShipPayload `json:"keep"`
+	AvoidEdges bool          `json:"avoid_edges"`
+	Spread     bool          `json:"spread"`
+	Commit     bool          `json:"commit"`
+	Seed       *int64        `json:"seed,omitempty"`
+}
+
+type FleetPreviewPayload struct {
+	GameID string        `json:"game_id"`
+	Ships  []ShipPayload `json:"ships"`
+}
+
 type FirePayload struct {
 	GameID string       `json:"game_id"`
 	Coord  CoordPayload `json:"coord"`
 }
 
+type FireSalvoPayload struct {
+	GameID string         `json:"game_id"`
+	Coords []CoordPayload `json:"coords"`
+}
+
+type ResignPayload struct {
+	GameID string `json:"game_id"`
+}
+
+type RematchPayload struct {
+	GameID string `json:"game_id"`
+}
+
+// ResumePayload asks for the events after LastSeq, as if the connection had
+// been opened with ?last_seq.
+type ResumePayload struct {
+	GameID  string `json:"game_id"`
+	LastSeq int64  `json:"last_seq"`
+}
+
+type ChatPayload struct {
+	GameID string `json:"game_id"`
+	Text   string `json:"text"`
+}
+
+type EmotePayload struct {
+	GameID string `json:"game_id"`
+	Emote  string `json:"emote"`
+}
+
+// ChatMessagePayload is a chat line or emote as broadcast to the room and as
+// listed in the chat history of the initial state. Kind is chat or emote.
+type ChatMessagePayload struct {
+	GameID string `json:"game_id"`
+	Kind   string `json:"kind"`
+	Player string `json:"player"`
+	Text   string `json:"text,omitempty"`
+	Emote  string `json:"emote,omitempty"`
+	At     int64  `json:"at"`
+}
+
 type CoordPayload struct {
 	Row int `json:"row"`
 	Col int `json:"col"`
 }
 
 type GameStatePayload struct {
-	GameID string             `json:"game_id"`
-	Turn   string             `json:"turn"`
-	Status string             `json:"status"`
-	Winner string             `json:"winner"`
-	Shots  map[string]string  `json:"shots"`
-	Ships  map[string][][]int `json:"ships"`
+	GameID        string             `json:"game_id"`
+	Player        string             `json:"player"`
+	Opponent      string             `json:"opponent"`
+	Turn          string             `json:"turn"`
+	TurnDeadline  int64              `json:"turn_deadline"`
+	Status        string             `json:"status"`
+	Winner        string             `json:"winner"`
+	Reason        string             `json:"reason,omitempty"`
+	Shots         map[string]string  `json:"shots"`
+	IncomingShots map[string]string  `json:"incoming_shots"`
+	Ships         map[string][][]int `json:"ships"`
+	Rules         RulesetPayload     `json:"rules"`
+	Clock         *ClockPayload      `json:"clock,omitempty"`
+	// Chat and Presence (player -> connected) are only sent on connect.
+	Chat     []ChatMessagePayload `json:"chat,omitempty"`
+	Presence map[string]bool      `json:"presence,omitempty"`
+}
+
+// SpectatorStatePayload keys Shots by the player who fired them and SunkShips
+// by the player who owned them.
+type SpectatorStatePayload struct {
+	GameID        string                        `json:"game_id"`
+	Turn          string                        `json:"turn"`
+	TurnDeadline  int64                         `json:"turn_deadline"`
+	Status        string                        `json:"status"`
+	Winner        string                        `json:"winner"`
+	Reason        string                        `json:"reason,omitempty"`
+	Shots         map[string]map[string]string  `json:"shots"`
+	SunkShips     map[string]map[string][][]int `json:"sunk_ships"`
+	Rules         RulesetPayload                `json:"rules"`
+	Clock         *ClockPayload                 `json:"clock,omitempty"`
+	RevealDelayMs int64                         `json:"reveal_delay_ms,omitempty"`
+	Chat          []ChatMessagePayload          `json:"chat,omitempty"`
+	Presence      map[string]bool               `json:"presence,omitempty"`
+}
+
+// SpectatorRevealPayload is the full game as of AsOf (unix ms), both fleets
+// included.
+type SpectatorRevealPayload struct {
+	GameID string                        `json:"game_id"`
+	AsOf   int64                         `json:"as_of"`
+	Status string                        `json:"status"`
+	Winner string                        `json:"winner"`
+	Reason string                        `json:"reason,omitempty"`
+	Shots  map[string]map[string]string  `json:"shots"`
+	Ships  map[string]map[string][][]int `json:"ships"`
+}
+
+type RulesetPayload struct {
+	Name         string            `json:"name"`
+	Width        int               `json:"width"`
+	Height       int               `json:"height"`
+	Fleet        []ShipSpecPayload `json:"fleet"`
+	Adjacency    string            `json:"adjacency"`
+	ShotsPerTurn int               `json:"shots_per_turn"`
+	Salvo        string            `json:"salvo"`
+}
+
+type ShipSpecPayload struct {
+	Type  string `json:"type"`
+	Size  int    `json:"size"`
+	Count int    `json:"count"`
 }
 
 type ShotResultPayload struct {
 	GameID  string       `json:"game_id"`
+	Player  string       `json:"player"`
 	Coord   CoordPayload `json:"coord"`
 	Outcome string       `json:"outcome"`
 	Ship    string       `json:"ship"`
 }
 
+type SalvoResultPayload struct {
+	GameID string              `json:"game_id"`
+	Player string              `json:"player"`
+	Shots  []ShotResultPayload `json:"shots"`
+}
+
 type TurnChangedPayload struct {
+	GameID   string `json:"game_id"`
+	Turn     string `json:"turn"`
+	Deadline int64  `json:"deadline"`
+}
+
+type TurnTimeoutPayload struct {
 	GameID string `json:"game_id"`
-	Turn   string `json:"turn"`
+	Player string `json:"player"`
+	Action string `json:"action"`
+}
+
+// ClockPayload reports both time banks in milliseconds as of the moment it
+// was sent; the Running player's bank keeps draining until they move.
+type ClockPayload struct {
+	GameID      string `json:"game_id"`
+	P1          int64  `json:"p1_ms"`
+	P2          int64  `json:"p2_ms"`
+	IncrementMs int64  `json:"increment_ms"`
+	Running     string `json:"running"`
 }
 
 type GameFinishedPayload struct {
 	GameID string `json:"game_id"`
 	Winner string `json:"winner"`
+	Reason string `json:"reason"`
+}
+
+// AckPayload confirms a client message sent with a request_id. Type is the
+// message type acknowledged; Result is the shot for fire and the shots for
+// fire_salvo.
+type AckPayload struct {
+	GameID string      `json:"game_id"`
+	Type   string      `json:"type"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// ResumedPayload ends a replay. Replayed counts the events after LastSeq that
+// were sent; chat and presence are current, as they are not in the event log.
+type ResumedPayload struct {
+	GameID   string               `json:"game_id"`
+	LastSeq  int64                `json:"last_seq"`
+	Replayed int                  `json:"replayed"`
+	Chat     []ChatMessagePayload `json:"chat,omitempty"`
+	Presence map[string]bool      `json:"presence,omitempty"`
+}
+
+type RematchRequestedPayload struct {
+	GameID string `json:"game_id"`
+	Player string `json:"player"`
+}
+
+// RematchStartedPayload is sent to each player separately and carries only
+// that player's token for the new game.
+type RematchStartedPayload struct {
+	GameID         string `json:"game_id"`
+	PreviousGameID string `json:"previous_game_id"`
+	Player         string `json:"player"`
+	Token          string `json:"token"`
+	Turn           string `json:"turn"`
+}
+
+// PresencePayload is sent as player_connected when a player's first
+// connection opens and as player_disconnected when their last one closes.
+type PresencePayload struct {
+	GameID string `json:"game_id"`
+	Player string `json:"player"`
 }
 
 type ErrorPayload struct {
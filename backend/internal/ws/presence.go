@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"context"
+
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+// presenceTTL outlives the ping period with room for a slow pong, so a live
+// connection never lapses between heartbeats.
+const presenceTTL = pongWait + writeWait
+
+// Server is the hub's PresenceHook: players coming online or going offline
+// are announced to the room as player_connected and player_disconnected.
+// Spectators are tracked without announcements, so games nobody watches skip
+// spectator updates. User rooms are not tracked.
+
+func (s *Server) Joined(client *Client) {
+	seat := presenceSeat(client)
+	if seat == "" {
+		return
+	}
+	first, err := s.Store.JoinPresence(context.Background(), client.GameID, seat, client.ID, presenceTTL)
+	if err != nil {
+		s.logPresenceError(client, err)
+		return
+	}
+	if first && client.Role == auth.RolePlayer {
+		s.announcePresence(client, "player_connected")
+	}
+}
+
+func (s *Server) Heartbeat(client *Client) {
+	seat := presenceSeat(client)
+	if seat == "" {
+		return
+	}
+	if err := s.Store.TouchPresence(context.Background(), client.GameID, seat, client.ID, presenceTTL); err != nil {
+		s.logPresenceError(client, err)
+	}
+}
+
+func (s *Server) Left(client *Client) {
+	seat := presenceSeat(client)
+	if seat == "" {
+		return
+	}
+	last, err := s.Store.LeavePresence(context.Background(), client.GameID, seat, client.ID)
+	if err != nil {
+		s.logPresenceError(client, err)
+		return
+	}
+	if last && client.Role == auth.RolePlayer {
+		s.announcePresence(client, "player_disconnected")
+	}
+}
+
+// presenceSeat is what client is tracked as: its seat for players,
+// redisstore.PresenceSpectator for spectators and nothing in user rooms.
+func presenceSeat(client *Client) string {
+	switch client.Role {
+	case auth.RolePlayer:
+		return client.Player
+	case auth.RoleSpectator:
+		return redisstore.PresenceSpectator
+	}
+	return ""
+}
+
+func (s *Server) announcePresence(client *Client, msgType string) {
+	msg := ServerMessage{Type: msgType, Payload: PresencePayload{GameID: client.GameID, Player: client.Player}}
+	if data, err := s.encode(client.GameID, msg); err == nil {
+		s.Hub.Broadcast(client.GameID, data)
+	}
+	if s.Logger != nil {
+		s.Logger.Printf("%s game_id=%s player=%s", msgType, client.GameID, client.Player)
+	}
+}
+
+func (s *Server) logPresenceError(client *Client, err error) {
+	if s.Logger != nil {
+		s.Logger.Printf("presence update failed game_id=%s player=%s err=%v", client.GameID, client.Player, err)
+	}
+}
+
+// presence is sent with the initial state. The AI seat is always present.
+func (s *Server) presence(gameID string, opponent string) map[string]bool {
+	presence, err := s.Store.Presence(context.Background(), gameID)
+	if err != nil {
+		return nil
+	}
+	if opponent == redisstore.OpponentAI {
+		presence["p2"] = true
+	}
+	return presence
+}
+
+// playerPresence is presence as seen by client, who is online by definition
+// even if their own entry has not been written yet.
+func (s *Server) playerPresence(client *Client, opponent string) map[string]bool {
+	presence := s.presence(client.GameID, opponent)
+	if presence != nil {
+		presence[client.Player] = true
+	}
+	return presence
+}
@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func readPresence(t *testing.T, ch <-chan []byte, want string) PresencePayload {
+	msg := readMessage(t, ch)
+	if msg.Type != want {
+		t.Fatalf("expected %s, got %s", want, msg.Type)
+	}
+	var presence PresencePayload
+	if err := json.Unmarshal(msg.Payload, &presence); err != nil {
+		t.Fatalf("unmarshal presence: %v", err)
+	}
+	return presence
+}
+
+func TestPresenceAnnouncedOnFirstAndLastConnection(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+	wsServer.Hub = NewHub()
+	wsServer.Hub.Presence = wsServer
+	go wsServer.Hub.Run()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	newClient := func(player, id string) *Client {
+		return &Client{Hub: wsServer.Hub, ID: id, GameID: meta.ID, Player: player, Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+	}
+
+	p2 := newClient("p2", "b")
+	registerClient(wsServer.Hub, p2)
+	if presence := readPresence(t, p2.Send, "player_connected"); presence.Player != "p2" {
+		t.Fatalf("expected p2 connected, got %+v", presence)
+	}
+
+	first, second := newClient("p1", "a1"), newClient("p1", "a2")
+	registerClient(wsServer.Hub, first)
+	if presence := readPresence(t, p2.Send, "player_connected"); presence.Player != "p1" {
+		t.Fatalf("expected p1 connected, got %+v", presence)
+	}
+	registerClient(wsServer.Hub, second)
+	watcher := &Client{Hub: wsServer.Hub, GameID: meta.ID, Role: auth.RoleSpectator, Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, watcher)
+	expectNoMessage(t, p2.Send)
+
+	reconnected := newClient("p2", "c")
+	wsServer.SendInitialState(reconnected)
+	if state := readStatePayload(t, reconnected.Send); !state.Presence["p1"] || !state.Presence["p2"] {
+		t.Fatalf("expected both players present, got %+v", state.Presence)
+	}
+
+	wsServer.Hub.unregister <- first
+	expectNoMessage(t, p2.Send)
+	wsServer.Hub.unregister <- second
+	if presence := readPresence(t, p2.Send, "player_disconnected"); presence.Player != "p1" {
+		t.Fatalf("expected p1 disconnected, got %+v", presence)
+	}
+
+	wsServer.SendInitialState(reconnected)
+	if state := readStatePayload(t, reconnected.Send); state.Presence["p1"] || !state.Presence["p2"] {
+		t.Fatalf("expected only p2 present, got %+v", state.Presence)
+	}
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+	"shipsgame/internal/bot"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func (s *Server) handleRematchRequest(client *Client, payload json.RawMessage) {
+	var rematch RematchPayload
+	if err := json.Unmarshal(payload, &rematch); err != nil {
+		s.sendError(client, "invalid rematch_request payload")
+		return
+	}
+	if rematch.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	ctx := context.Background()
+	if err := s.Store.RequestRematch(ctx, rematch.GameID, client.Player); err != nil {
+		s.sendError(client, err.Error())
+		return
+	}
+
+	requested := ServerMessage{
+		Type:    "rematch_requested",
+		Payload: RematchRequestedPayload{GameID: rematch.GameID, Player: client.Player},
+	}
+	if data, err := s.encode(rematch.GameID, requested); err == nil {
+		s.Hub.Broadcast(rematch.GameID, data)
+	}
+
+	meta, err := s.Store.GetMeta(ctx, rematch.GameID)
+	if err == nil && meta.Opponent == redisstore.OpponentAI && s.Bot != nil {
+		s.startRematch(client, rematch.GameID, bot.Seat)
+	}
+}
+
+func (s *Server) handleRematchAccept(client *Client, payload json.RawMessage) {
+	var rematch RematchPayload
+	if err := json.Unmarshal(payload, &rematch); err != nil {
+		s.sendError(client, "invalid rematch_accept payload")
+		return
+	}
+	if rematch.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	s.startRematch(client, rematch.GameID, client.Player)
+}
+
+// startRematch creates the follow-up game on behalf of accepter and hands
+// each human player a token for their seat in it. Tokens are sent only to the
+// player they belong to.
+func (s *Server) startRematch(client *Client, gameID string, accepter string) {
+	ctx := context.Background()
+	meta, err := s.Store.AcceptRematch(ctx, gameID, accepter)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("rematch failed game_id=%s player=%s err=%v", gameID, accepter, err)
+		}
+		s.sendError(client, err.Error())
+		return
+	}
+
+	players := []string{"p1", "p2"}
+	if meta.Opponent == redisstore.OpponentAI {
+		if err := s.Bot.PlaceFleet(ctx, meta.ID); err != nil {
+			if s.Logger != nil {
+				s.Logger.Printf("rematch bot placement failed game_id=%s err=%v", meta.ID, err)
+			}
+			s.sendError(client, "failed to place ai fleet")
+			return
+		}
+		players = []string{"p1"}
+	}
+
+	for _, player := range players {
+		userID := meta.P1UserID
+		if player == "p2" {
+			userID = meta.P2UserID
+		}
+		token, err := s.signToken(meta.ID, player, userID)
+		if err != nil {
+			if s.Logger != nil {
+				s.Logger.Printf("rematch token failed game_id=%s player=%s err=%v", meta.ID, player, err)
+			}
+			continue
+		}
+		started := ServerMessage{
+			Type: "rematch_started",
+			Payload: RematchStartedPayload{
+				GameID:         meta.ID,
+				PreviousGameID: gameID,
+				Player:         player,
+				Token:          token,
+				Turn:           meta.Turn,
+			},
+		}
+		if data, err := s.encode(gameID, started); err == nil {
+			s.Hub.SendToPlayer(gameID, player, data)
+		}
+	}
+
+	if s.Logger != nil {
+		s.Logger.Printf("rematch started game_id=%s previous_game_id=%s starting_player=%s", meta.ID, gameID, meta.Turn)
+	}
+}
+
+func (s *Server) signToken(gameID string, player string, userID string) (string, error) {
+	return auth.SignToken(s.JWTSecret, auth.Claims{
+		GameID: gameID,
+		Player: player,
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+		},
+	})
+}
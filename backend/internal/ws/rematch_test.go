@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func TestRematchRequestAndAccept(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	if _, err := store.Resign(context.Background(), meta.ID, "p2"); err != nil {
+		t.Fatalf("resign: %v", err)
+	}
+
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 4)}
+	p2 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, p1)
+	registerClient(wsServer.Hub, p2)
+
+	body, _ := json.Marshal(RematchPayload{GameID: meta.ID})
+	request, _ := json.Marshal(ClientMessage{Type: "rematch_request", Payload: body})
+	wsServer.handleMessage(p1, request)
+
+	msg := readMessage(t, p2.Send)
+	if msg.Type != "rematch_requested" {
+		t.Fatalf("expected rematch_requested, got %s", msg.Type)
+	}
+	readMessage(t, p1.Send)
+
+	accept, _ := json.Marshal(ClientMessage{Type: "rematch_accept", Payload: body})
+	wsServer.handleMessage(p2, accept)
+
+	var newGameID string
+	for _, client := range []*Client{p1, p2} {
+		msg := readMessage(t, client.Send)
+		if msg.Type != "rematch_started" {
+			t.Fatalf("expected rematch_started, got %s", msg.Type)
+		}
+		var started RematchStartedPayload
+		if err := json.Unmarshal(msg.Payload, &started); err != nil {
+			t.Fatalf("unmarshal rematch: %v", err)
+		}
+		if started.Player != client.Player || started.PreviousGameID != meta.ID || started.Turn != "p2" {
+			t.Fatalf("unexpected rematch payload: %+v", started)
+		}
+
+		claims, err := auth.ParseToken(started.Token, wsServer.JWTSecret)
+		if err != nil {
+			t.Fatalf("parse token: %v", err)
+		}
+		if claims.GameID != started.GameID || claims.Player != client.Player {
+			t.Fatalf("unexpected token claims: %+v", claims)
+		}
+		if newGameID != "" && newGameID != started.GameID {
+			t.Fatalf("players got different games: %s and %s", newGameID, started.GameID)
+		}
+		newGameID = started.GameID
+	}
+
+	wsServer.handleMessage(p2, accept)
+	if msg := readMessage(t, p2.Send); msg.Type != "error" {
+		t.Fatalf("expected error on second accept, got %s", msg.Type)
+	}
+}
@@ -0,0 +1,169 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+// maxReplayEvents is the largest gap replayed event by event; a player who
+// missed more gets a fresh game_state instead.
+const maxReplayEvents = 100
+
+// A player reconnecting with last_seq gets the events they missed rebuilt
+// from the game's event log, each stamped with its own seq, followed by
+// turn_changed, clock and resumed. Live messages may arrive interleaved with
+// the replay; event messages with a seq the client has already applied can
+// be dropped. Setup events (joined, placed) change too much of the state to
+// replay, so a gap containing them falls back to game_state as well.
+
+func (s *Server) handleResume(client *Client, payload json.RawMessage) {
+	var resume ResumePayload
+	if err := json.Unmarshal(payload, &resume); err != nil {
+		s.sendError(client, "invalid resume payload")
+		return
+	}
+	if resume.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	s.resume(client, resume.LastSeq)
+}
+
+func (s *Server) resume(client *Client, lastSeq int64) {
+	ctx := context.Background()
+	seq, err := s.Store.EventSeq(ctx, client.GameID)
+	if err != nil || lastSeq <= 0 || lastSeq > seq {
+		s.sendSnapshot(client)
+		return
+	}
+	events, err := s.Store.GetEvents(ctx, client.GameID, lastSeq)
+	if err != nil || !replayable(events) {
+		s.sendSnapshot(client)
+		return
+	}
+
+	for _, msg := range replayMessages(client.GameID, events) {
+		if data, err := json.Marshal(msg); err == nil {
+			client.Send <- data
+		}
+	}
+
+	meta, err := s.Store.GetMeta(ctx, client.GameID)
+	if err != nil {
+		s.sendSnapshot(client)
+		return
+	}
+	turnMsg := ServerMessage{
+		Type:    "turn_changed",
+		Seq:     meta.EventSeq,
+		Payload: TurnChangedPayload{GameID: meta.ID, Turn: meta.Turn, Deadline: unixMilli(meta.TurnDeadline)},
+	}
+	if data, err := s.encode(meta.ID, turnMsg); err == nil {
+		client.Send <- data
+	}
+	if meta.Clock.Enabled() {
+		if data, err := s.encode(meta.ID, ServerMessage{Type: "clock", Seq: meta.EventSeq, Payload: clockPayload(meta.ID, meta.Clock)}); err == nil {
+			client.Send <- data
+		}
+	}
+	resumed := ServerMessage{
+		Type: "resumed",
+		Seq:  meta.EventSeq,
+		Payload: ResumedPayload{
+			GameID:   meta.ID,
+			LastSeq:  lastSeq,
+			Replayed: len(events),
+			Chat:     s.chatHistory(meta.ID),
+			Presence: s.playerPresence(client, meta.Opponent),
+		},
+	}
+	if data, err := s.encode(meta.ID, resumed); err == nil {
+		client.Send <- data
+	}
+}
+
+func replayable(events []redisstore.GameEvent) bool {
+	if len(events) > maxReplayEvents {
+		return false
+	}
+	for _, event := range events {
+		if event.Type == redisstore.EventJoined || event.Type == redisstore.EventPlaced {
+			return false
+		}
+	}
+	return true
+}
+
+// replayMessages turns events into the messages sent when they happened. A
+// shot and the sunk event that follows it become one shot_result; salvos are
+// replayed shot by shot.
+func replayMessages(gameID string, events []redisstore.GameEvent) []ServerMessage {
+	messages := make([]ServerMessage, 0, len(events))
+	for i := 0; i < len(events); i++ {
+		event := events[i]
+		switch event.Type {
+		case redisstore.EventShot, redisstore.EventSunk:
+			shot := ShotResultPayload{
+				GameID:  gameID,
+				Player:  event.Player,
+				Coord:   replayCoord(event.Coord),
+				Outcome: event.Outcome,
+				Ship:    event.Ship,
+			}
+			if event.Type == redisstore.EventSunk {
+				shot.Outcome = "sunk"
+			} else if i+1 < len(events) && events[i+1].Type == redisstore.EventSunk && events[i+1].Coord == event.Coord {
+				i++
+				event = events[i]
+				shot.Outcome = "sunk"
+				shot.Ship = event.Ship
+			}
+			messages = append(messages, ServerMessage{Type: "shot_result", Seq: event.Seq, Payload: shot})
+		case redisstore.EventTurnSkipped:
+			messages = append(messages, ServerMessage{
+				Type:    "turn_timeout",
+				Seq:     event.Seq,
+				Payload: TurnTimeoutPayload{GameID: gameID, Player: event.Player, Action: redisstore.TimeoutSkip},
+			})
+		case redisstore.EventFinished:
+			messages = append(messages, ServerMessage{
+				Type:    "game_finished",
+				Seq:     event.Seq,
+				Payload: GameFinishedPayload{GameID: gameID, Winner: event.Winner, Reason: event.Reason},
+			})
+		}
+	}
+	return messages
+}
+
+func replayCoord(key string) CoordPayload {
+	var coord CoordPayload
+	_, _ = fmt.Sscanf(key, "%d,%d", &coord.Row, &coord.Col)
+	return coord
+}
+
+// encode marshals msg for gameID. Moves, chat and their acks carry the seq
+// returned by the script that stored them, and snapshots the seq they were
+// read at. Only messages with neither, such as errors, presence, rematch and
+// spectator_reveal, cost a read of the current seq here.
+func (s *Server) encode(gameID string, msg ServerMessage) ([]byte, error) {
+	if msg.Seq == 0 {
+		msg.Seq = s.eventSeq(gameID)
+	}
+	return json.Marshal(msg)
+}
+
+func (s *Server) eventSeq(gameID string) int64 {
+	if gameID == "" || isUserRoom(gameID) {
+		return 0
+	}
+	seq, err := s.Store.EventSeq(context.Background(), gameID)
+	if err != nil {
+		return 0
+	}
+	return seq
+}
@@ -0,0 +1,144 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"shipsgame/internal/auth"
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func TestReconnectReplaysMissedEvents(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+	wsServer.SendInitialState(p1)
+	snapshot := readMessage(t, p1.Send)
+	if snapshot.Type != "game_state" || snapshot.Seq != 3 {
+		t.Fatalf("expected game_state at seq 3, got %s at %d", snapshot.Type, snapshot.Seq)
+	}
+
+	sendClientMessage(wsServer, p1, "fire", FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 2, Col: 0}})
+	if msg := readMessage(t, p1.Send); msg.Type != "shot_result" || msg.Seq != 4 {
+		t.Fatalf("expected shot_result at seq 4, got %s at %d", msg.Type, msg.Seq)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "turn_changed" || msg.Seq != 4 {
+		t.Fatalf("expected turn_changed at seq 4, got %s at %d", msg.Type, msg.Seq)
+	}
+
+	if _, err := store.Fire(ctx, meta.ID, "p2", game.Coord{Row: 5, Col: 5}); err != nil {
+		t.Fatalf("p2 fire: %v", err)
+	}
+	if _, err := store.Fire(ctx, meta.ID, "p1", game.Coord{Row: 2, Col: 1}); err != nil {
+		t.Fatalf("p1 fire: %v", err)
+	}
+
+	reconnected := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, LastSeq: 4, Send: make(chan []byte, 8)}
+	wsServer.SendInitialState(reconnected)
+
+	want := []struct {
+		msgType string
+		seq     int64
+		outcome string
+	}{
+		{"shot_result", 5, "miss"},
+		{"shot_result", 7, "sunk"},
+		{"game_finished", 8, ""},
+		{"turn_changed", 8, ""},
+		{"resumed", 8, ""},
+	}
+	for _, w := range want {
+		msg := readMessage(t, reconnected.Send)
+		if msg.Type != w.msgType || msg.Seq != w.seq {
+			t.Fatalf("expected %s at seq %d, got %s at %d", w.msgType, w.seq, msg.Type, msg.Seq)
+		}
+		switch msg.Type {
+		case "shot_result":
+			var shot ShotResultPayload
+			if err := json.Unmarshal(msg.Payload, &shot); err != nil || shot.Outcome != w.outcome {
+				t.Fatalf("expected %s shot, got %+v err=%v", w.outcome, shot, err)
+			}
+			if shot.Outcome == "sunk" && (shot.Ship != "destroyer" || shot.Coord != (CoordPayload{Row: 2, Col: 1})) {
+				t.Fatalf("unexpected sunk shot %+v", shot)
+			}
+		case "game_finished":
+			var finished GameFinishedPayload
+			if err := json.Unmarshal(msg.Payload, &finished); err != nil || finished.Winner != "p1" {
+				t.Fatalf("unexpected game_finished %+v err=%v", finished, err)
+			}
+		case "resumed":
+			var resumed ResumedPayload
+			if err := json.Unmarshal(msg.Payload, &resumed); err != nil || resumed.LastSeq != 4 || resumed.Replayed != 4 || !resumed.Presence["p1"] {
+				t.Fatalf("unexpected resumed %+v err=%v", resumed, err)
+			}
+		}
+	}
+	expectNoMessage(t, reconnected.Send)
+}
+
+func TestResumeFallsBackToSnapshot(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+
+	// seq 1 leaves both placements unseen, seq 99 was never reached.
+	for _, lastSeq := range []int64{1, 99} {
+		sendClientMessage(wsServer, p1, "resume", ResumePayload{GameID: meta.ID, LastSeq: lastSeq})
+		if msg := readMessage(t, p1.Send); msg.Type != "game_state" || msg.Seq != 3 {
+			t.Fatalf("expected game_state at seq 3 for last_seq %d, got %s at %d", lastSeq, msg.Type, msg.Seq)
+		}
+	}
+
+	sendClientMessage(wsServer, p1, "resume", ResumePayload{GameID: meta.ID, LastSeq: 3})
+	if msg := readMessage(t, p1.Send); msg.Type != "turn_changed" {
+		t.Fatalf("expected nothing to replay, got %s", msg.Type)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "resumed" {
+		t.Fatalf("expected resumed, got %s", msg.Type)
+	}
+
+	sendClientMessage(wsServer, p1, "resume", ResumePayload{GameID: "other", LastSeq: 3})
+	if msg := readMessage(t, p1.Send); msg.Type != "error" {
+		t.Fatalf("expected game mismatch error, got %s", msg.Type)
+	}
+}
+
+func TestEventMessagesKeepTheirOwnSeq(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+
+	result, err := store.Fire(ctx, meta.ID, "p1", game.Coord{Row: 9, Col: 9})
+	if err != nil {
+		t.Fatalf("p1 fire: %v", err)
+	}
+	if _, err := store.Fire(ctx, meta.ID, "p2", game.Coord{Row: 9, Col: 9}); err != nil {
+		t.Fatalf("p2 fire: %v", err)
+	}
+
+	// p1's shot is announced after p2 has already fired, as when two
+	// handlers race; it still carries the seq it was logged at.
+	wsServer.announceExpiry(redisstore.TurnExpiry{GameID: meta.ID, Player: "p1", Coord: game.Coord{Row: 9, Col: 9}, Result: result, Seq: result.Seq})
+	if msg := readMessage(t, p1.Send); msg.Type != "turn_timeout" || msg.Seq != 4 {
+		t.Fatalf("expected turn_timeout at seq 4, got %s at %d", msg.Type, msg.Seq)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "shot_result" || msg.Seq != 4 {
+		t.Fatalf("expected shot_result at seq 4, got %s at %d", msg.Type, msg.Seq)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "turn_changed" || msg.Seq != 5 {
+		t.Fatalf("expected turn_changed at seq 5, got %s at %d", msg.Type, msg.Seq)
+	}
+}
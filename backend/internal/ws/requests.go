@@ -0,0 +1,98 @@
+package ws
+
+import (
+	"context"
+	"time"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const (
+	maxRequestIDLength = 64
+	// requestTTL is how long a request_id is remembered; a retry after that
+	// is treated as a new request.
+	requestTTL = 10 * time.Minute
+)
+
+// idempotentTypes are the client messages whose request_id is remembered, so
+// a retry returns the original reply instead of acting twice.
+var idempotentTypes = map[string]bool{
+	"fire":        true,
+	"place_ships": true,
+}
+
+// clientRequest is the client message being handled. Replies echo its id,
+// and the last one is kept for idempotent retries.
+type clientRequest struct {
+	id      string
+	msgType string
+	// reply is kept for retries; nil releases the request_id instead.
+	reply   []byte
+	replied bool
+}
+
+// reply sends msg to client alone, echoing the request_id being handled.
+func (s *Server) reply(client *Client, msg ServerMessage) {
+	request := client.request
+	if request != nil {
+		msg.RequestID = request.id
+	}
+	data, err := s.encode(client.GameID, msg)
+	if err != nil {
+		return
+	}
+	if request != nil {
+		request.reply = data
+		request.replied = true
+	}
+	client.Send <- data
+}
+
+// ack acknowledges a request that carries a request_id, once. Requests that
+// already got a reply, such as an error or fleet_preview, get no ack. seq is
+// that of the move acknowledged, 0 when there is none.
+func (s *Server) ack(client *Client, seq int64, result any) {
+	request := client.request
+	if request == nil || request.id == "" || request.replied {
+		return
+	}
+	s.reply(client, ServerMessage{Type: "ack", Seq: seq, Payload: AckPayload{GameID: client.GameID, Type: request.msgType, Result: result}})
+}
+
+// sendStoreError replies with a failed store call. Failing to reach Redis
+// says nothing about the move, so that reply is not kept for the request_id
+// and a retry is handled afresh.
+func (s *Server) sendStoreError(client *Client, err error) {
+	s.sendError(client, err.Error())
+	if client.request != nil && redisstore.IsUnavailable(err) {
+		client.request.reply = nil
+	}
+}
+
+// claimRequest reserves the request_id being handled. A duplicate gets the
+// stored reply, or an error while the original is still in flight, and is
+// not handled again.
+func (s *Server) claimRequest(client *Client) bool {
+	request := client.request
+	reply, claimed, err := s.Store.ClaimRequest(context.Background(), client.GameID, client.Player, request.id, requestTTL)
+	if err != nil {
+		s.sendError(client, "failed to check request_id")
+		return false
+	}
+	if claimed {
+		return true
+	}
+	if len(reply) == 0 {
+		s.sendError(client, "request already in progress")
+		return false
+	}
+	client.Send <- reply
+	return false
+}
+
+func (s *Server) completeRequest(client *Client) {
+	request := client.request
+	if err := s.Store.CompleteRequest(context.Background(), client.GameID, client.Player, request.id, request.reply); err != nil && s.Logger != nil {
+		s.Logger.Printf("request store failed game_id=%s player=%s request_id=%s err=%v", client.GameID, client.Player, request.id, err)
+	}
+}
@@ -0,0 +1,139 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"shipsgame/internal/auth"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func sendRequest(wsServer *Server, client *Client, requestID string, msgType string, payload any) {
+	body, _ := json.Marshal(payload)
+	data, _ := json.Marshal(ClientMessage{Type: msgType, RequestID: requestID, Payload: body})
+	wsServer.handleMessage(client, data)
+}
+
+func readReply(t *testing.T, ch <-chan []byte, msgType string, requestID string) []byte {
+	t.Helper()
+	select {
+	case data := <-ch:
+		var msg ServerMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != msgType || msg.RequestID != requestID {
+			t.Fatalf("expected %s for %s, got %s err=%v", msgType, requestID, data, err)
+		}
+		return data
+	default:
+		t.Fatalf("expected %s for %s, got nothing", msgType, requestID)
+	}
+	return nil
+}
+
+func TestDuplicateFireReturnsOriginalReply(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+
+	fire := FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 2, Col: 0}}
+	sendRequest(wsServer, p1, "r1", "fire", fire)
+	ack := readReply(t, p1.Send, "ack", "r1")
+	if !strings.Contains(string(ack), `"outcome":"hit"`) || !strings.Contains(string(ack), `"seq":4`) {
+		t.Fatalf("expected ack with the shot at seq 4, got %s", ack)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "shot_result" {
+		t.Fatalf("expected shot_result, got %s", msg.Type)
+	}
+	if msg := readMessage(t, p1.Send); msg.Type != "turn_changed" {
+		t.Fatalf("expected turn_changed, got %s", msg.Type)
+	}
+
+	sendRequest(wsServer, p1, "r1", "fire", fire)
+	if retry := readReply(t, p1.Send, "ack", "r1"); string(retry) != string(ack) {
+		t.Fatalf("expected original ack %s, got %s", ack, retry)
+	}
+	expectNoMessage(t, p1.Send)
+
+	sendRequest(wsServer, p1, "r2", "fire", FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 3, Col: 3}})
+	failed := readReply(t, p1.Send, "error", "r2")
+	sendRequest(wsServer, p1, "r2", "fire", FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 3, Col: 3}})
+	if retry := readReply(t, p1.Send, "error", "r2"); string(retry) != string(failed) {
+		t.Fatalf("expected original error %s, got %s", failed, retry)
+	}
+
+	sendRequest(wsServer, p1, strings.Repeat("x", maxRequestIDLength+1), "fire", fire)
+	readReply(t, p1.Send, "error", "")
+}
+
+func TestDuplicatePlaceShipsIsAcknowledgedOnce(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+
+	place := PlaceShipsPayload{
+		GameID: meta.ID,
+		Ships:  []ShipPayload{{Type: "destroyer", Cells: []CoordPayload{{Row: 0, Col: 0}, {Row: 0, Col: 1}}}},
+	}
+	// The ack goes straight to the client and the state through the hub, so
+	// they may arrive in either order.
+	sendRequest(wsServer, p1, "place-1", "place_ships", place)
+	var ack []byte
+	for i := 0; i < 2; i++ {
+		data := <-p1.Send
+		var msg ServerMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("unmarshal message: %v", err)
+		}
+		if msg.Type == "ack" && msg.RequestID == "place-1" {
+			ack = data
+		} else if msg.Type != "game_state" {
+			t.Fatalf("expected game_state and ack, got %s", data)
+		}
+	}
+	if ack == nil {
+		t.Fatalf("expected ack for place-1")
+	}
+
+	sendRequest(wsServer, p1, "place-1", "place_ships", place)
+	if retry := readReply(t, p1.Send, "ack", "place-1"); string(retry) != string(ack) {
+		t.Fatalf("expected original ack %s, got %s", ack, retry)
+	}
+	expectNoMessage(t, p1.Send)
+
+	sendRequest(wsServer, p1, "place-2", "place_ships", place)
+	readReply(t, p1.Send, "error", "place-2")
+}
+
+func TestUnavailableStoreReleasesRequestID(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, p1)
+
+	// A first attempt whose Fire timed out talking to Redis.
+	p1.request = &clientRequest{id: "r1", msgType: "fire"}
+	if !wsServer.claimRequest(p1) {
+		t.Fatalf("expected r1 claimed")
+	}
+	wsServer.sendStoreError(p1, context.DeadlineExceeded)
+	wsServer.completeRequest(p1)
+	p1.request = nil
+	readReply(t, p1.Send, "error", "r1")
+
+	sendRequest(wsServer, p1, "r1", "fire", FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 2, Col: 0}})
+	if ack := readReply(t, p1.Send, "ack", "r1"); !strings.Contains(string(ack), `"outcome":"hit"`) {
+		t.Fatalf("expected the retry to fire, got %s", ack)
+	}
+}
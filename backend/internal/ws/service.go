@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"math/rand"
 	"net/http"
 	"strings"
+	"time"
 
 	"shipsgame/internal/auth"
+	"shipsgame/internal/bot"
 	"shipsgame/internal/game"
 	redisstore "shipsgame/internal/store/redis"
 )
@@ -16,8 +19,12 @@ import (
 type Server struct {
 	Hub       *Hub
 	Store     *redisstore.Client
+	Bot       *bot.Player
 	JWTSecret string
 	Logger    *log.Logger
+
+	// ChatFilter, when set, screens every chat message before it is sent.
+	ChatFilter ChatFilter
 }
 
 func (s *Server) Handler() http.Handler {
@@ -29,21 +36,28 @@ func (s *Server) Handler() http.Handler {
 	return handler
 }
 
-func (s *Server) authenticate(r *http.Request) (string, string, error) {
+func (s *Server) authenticate(r *http.Request) (Identity, error) {
 	header := r.Header.Get("Authorization")
 	if header == "" {
-		return "", "", errors.New("missing authorization")
+		return Identity{}, errors.New("missing authorization")
 	}
 	parts := strings.SplitN(header, " ", 2)
 	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-		return "", "", errors.New("invalid authorization header")
+		return Identity{}, errors.New("invalid authorization header")
 	}
 
 	claims, err := auth.ParseToken(parts[1], s.JWTSecret)
 	if err != nil {
-		return "", "", err
+		user, userErr := auth.ParseUserToken(parts[1], s.JWTSecret)
+		if userErr != nil {
+			return Identity{}, err
+		}
+		return Identity{GameID: userRoom(user.UserID)}, nil
+	}
+	if claims.IsSpectator() {
+		return Identity{GameID: claims.GameID, Role: auth.RoleSpectator}, nil
 	}
-	return claims.GameID, claims.Player, nil
+	return Identity{GameID: claims.GameID, Player: claims.Player, Role: auth.RolePlayer}, nil
 }
 
 func (s *Server) handleMessage(client *Client, message []byte) {
@@ -52,12 +66,48 @@ func (s *Server) handleMessage(client *Client, message []byte) {
 		s.sendError(client, "invalid message")
 		return
 	}
+	if len(envelope.RequestID) > maxRequestIDLength {
+		s.sendError(client, "invalid request_id")
+		return
+	}
+	client.request = &clientRequest{id: envelope.RequestID, msgType: envelope.Type}
+	defer func() { client.request = nil }()
+
+	if isUserRoom(client.GameID) || client.Role == auth.RoleSpectator {
+		s.sendError(client, "connection is read-only")
+		return
+	}
+	if envelope.RequestID != "" {
+		if idempotentTypes[envelope.Type] {
+			if !s.claimRequest(client) {
+				return
+			}
+			defer s.completeRequest(client)
+		}
+		defer s.ack(client, 0, nil)
+	}
 
 	switch envelope.Type {
 	case "place_ships":
 		s.handlePlaceShips(client, envelope.Payload)
+	case "auto_place":
+		s.handleAutoPlace(client, envelope.Payload)
 	case "fire":
 		s.handleFire(client, envelope.Payload)
+	case "fire_salvo":
+		s.handleFireSalvo(client, envelope.Payload)
+	case "resign":
+		s.handleResign(client, envelope.Payload)
+	case "rematch_request":
+		s.handleRematchRequest(client, envelope.Payload)
+	case "rematch_accept":
+		s.handleRematchAccept(client, envelope.Payload)
+	case "resume":
+		s.handleResume(client, envelope.Payload)
+	case "chat":
+		s.handleChat(client, envelope.Payload)
+	case "emote":
+		s.handleEmote(client, envelope.Payload)
 	default:
 		s.sendError(client, "unknown message type")
 	}
@@ -74,31 +124,83 @@ func (s *Server) handlePlaceShips(client *Client, payload json.RawMessage) {
 		return
 	}
 
-	placement := make(redisstore.ShipsPlacement, len(place.Ships))
-	for _, ship := range place.Ships {
-		cells := make([]game.Coord, 0, len(ship.Cells))
-		for _, cell := range ship.Cells {
-			cells = append(cells, game.Coord{Row: cell.Row, Col: cell.Col})
+	s.commitPlacement(client, place.GameID, placementFromPayload(place.Ships))
+}
+
+func (s *Server) handleAutoPlace(client *Client, payload json.RawMessage) {
+	var auto AutoPlacePayload
+	if err := json.Unmarshal(payload, &auto); err != nil {
+		s.sendError(client, "invalid auto_place payload")
+		return
+	}
+	if auto.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	meta, err := s.Store.GetMeta(context.Background(), auto.GameID)
+	if err != nil {
+		s.sendError(client, err.Error())
+		return
+	}
+
+	seed := time.Now().UnixNano()
+	if auto.Seed != nil {
+		seed = *auto.Seed
+	}
+	ships, err := game.RandomFleet(meta.Rules, rand.New(rand.NewSource(seed)), game.FleetConstraints{
+		Fixed:      placementFromPayload(auto.Keep),
+		AvoidEdges: auto.AvoidEdges,
+		Spread:     auto.Spread,
+	})
+	if err != nil {
+		s.sendError(client, err.Error())
+		return
+	}
+
+	if auto.Commit {
+		s.commitPlacement(client, auto.GameID, redisstore.ShipsPlacement(ships))
+		return
+	}
+
+	preview := make([]ShipPayload, 0, len(ships))
+	for _, id := range meta.Rules.ShipIDs() {
+		cells := make([]CoordPayload, 0, len(ships[id]))
+		for _, cell := range ships[id] {
+			cells = append(cells, CoordPayload{Row: cell.Row, Col: cell.Col})
 		}
-		placement[game.ShipType(strings.ToLower(ship.Type))] = cells
+		preview = append(preview, ShipPayload{Type: string(id), Cells: cells})
 	}
+	s.reply(client, ServerMessage{Type: "fleet_preview", Payload: FleetPreviewPayload{GameID: auto.GameID, Ships: preview}})
+}
 
-	if err := s.Store.PlaceShips(context.Background(), place.GameID, client.Player, placement); err != nil {
+func (s *Server) commitPlacement(client *Client, gameID string, placement redisstore.ShipsPlacement) {
+	if err := s.Store.PlaceShips(context.Background(), gameID, client.Player, placement); err != nil {
 		if s.Logger != nil {
-			s.Logger.Printf("ships place failed game_id=%s player=%s err=%v", place.GameID, client.Player, err)
+			s.Logger.Printf("ships place failed game_id=%s player=%s err=%v", gameID, client.Player, err)
 		}
-		s.sendError(client, err.Error())
+		s.sendStoreError(client, err)
 		return
 	}
 
 	if s.Logger != nil {
-		s.Logger.Printf("ships placed game_id=%s player=%s", place.GameID, client.Player)
+		s.Logger.Printf("ships placed game_id=%s player=%s", gameID, client.Player)
 	}
 
-	state, err := s.Store.GetState(context.Background(), place.GameID, client.Player)
-	if err == nil {
-		s.broadcastState(place.GameID, state)
+	s.broadcastState(gameID)
+	s.playBot(gameID)
+}
+
+func placementFromPayload(ships []ShipPayload) redisstore.ShipsPlacement {
+	placement := make(redisstore.ShipsPlacement, len(ships))
+	for _, ship := range ships {
+		cells := make([]game.Coord, 0, len(ship.Cells))
+		for _, cell := range ship.Cells {
+			cells = append(cells, game.Coord{Row: cell.Row, Col: cell.Col})
+		}
+		placement[game.ShipType(strings.ToLower(ship.Type))] = cells
 	}
+	return placement
 }
 
 func (s *Server) handleFire(client *Client, payload json.RawMessage) {
@@ -117,7 +219,10 @@ func (s *Server) handleFire(client *Client, payload json.RawMessage) {
 		if s.Logger != nil {
 			s.Logger.Printf("shot failed game_id=%s player=%s coord=%d,%d err=%v", fire.GameID, client.Player, fire.Coord.Row, fire.Coord.Col, err)
 		}
-		s.sendError(client, err.Error())
+		s.sendStoreError(client, err)
+		if errors.Is(err, redisstore.ErrClockExpired) {
+			s.broadcastTurn(fire.GameID)
+		}
 		return
 	}
 
@@ -125,99 +230,320 @@ func (s *Server) handleFire(client *Client, payload json.RawMessage) {
 		s.Logger.Printf("shot fired game_id=%s player=%s coord=%d,%d outcome=%s", fire.GameID, client.Player, fire.Coord.Row, fire.Coord.Col, outcomeLabel(result.Outcome))
 	}
 
+	shot := shotPayload(fire.GameID, client.Player, fire.Coord, result)
+	s.ack(client, result.Seq, shot)
 	shotMsg := ServerMessage{
-		Type: "shot_result",
-		Payload: ShotResultPayload{
-			GameID:  fire.GameID,
-			Coord:   fire.Coord,
-			Outcome: outcomeLabel(result.Outcome),
-			Ship:    string(result.ShipType),
-		},
+		Type:    "shot_result",
+		Seq:     result.Seq,
+		Payload: shot,
 	}
-	if data, err := json.Marshal(shotMsg); err == nil {
+	if data, err := s.encode(fire.GameID, shotMsg); err == nil {
 		s.Hub.Broadcast(fire.GameID, data)
 	}
 
-	meta, err := s.Store.GetMeta(context.Background(), fire.GameID)
-	if err == nil {
-		turnMsg := ServerMessage{
-			Type: "turn_changed",
-			Payload: TurnChangedPayload{
-				GameID: fire.GameID,
-				Turn:   meta.Turn,
-			},
+	s.broadcastTurn(fire.GameID)
+	s.playBot(fire.GameID)
+}
+
+func (s *Server) handleFireSalvo(client *Client, payload json.RawMessage) {
+	var salvo FireSalvoPayload
+	if err := json.Unmarshal(payload, &salvo); err != nil {
+		s.sendError(client, "invalid fire_salvo payload")
+		return
+	}
+	if salvo.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	coords := make([]game.Coord, 0, len(salvo.Coords))
+	for _, coord := range salvo.Coords {
+		coords = append(coords, game.Coord{Row: coord.Row, Col: coord.Col})
+	}
+
+	results, err := s.Store.FireSalvo(context.Background(), salvo.GameID, client.Player, coords)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("salvo failed game_id=%s player=%s shots=%d err=%v", salvo.GameID, client.Player, len(coords), err)
 		}
-		if data, err := json.Marshal(turnMsg); err == nil {
-			s.Hub.Broadcast(fire.GameID, data)
+		s.sendStoreError(client, err)
+		if errors.Is(err, redisstore.ErrClockExpired) {
+			s.broadcastTurn(salvo.GameID)
 		}
+		return
+	}
 
-		if meta.Status == "finished" {
-			finished := ServerMessage{
-				Type: "game_finished",
-				Payload: GameFinishedPayload{
-					GameID: fire.GameID,
-					Winner: meta.Winner,
-				},
-			}
-			if data, err := json.Marshal(finished); err == nil {
-				s.Hub.Broadcast(fire.GameID, data)
-			}
-		}
+	if s.Logger != nil {
+		s.Logger.Printf("salvo fired game_id=%s player=%s shots=%d", salvo.GameID, client.Player, len(coords))
+	}
+
+	shots := make([]ShotResultPayload, 0, len(results))
+	for i, result := range results {
+		shots = append(shots, shotPayload(salvo.GameID, client.Player, salvo.Coords[i], result))
+	}
+	s.ack(client, results[len(results)-1].Seq, shots)
+	salvoMsg := ServerMessage{
+		Type: "salvo_result",
+		Seq:  results[len(results)-1].Seq,
+		Payload: SalvoResultPayload{
+			GameID: salvo.GameID,
+			Player: client.Player,
+			Shots:  shots,
+		},
 	}
+	if data, err := s.encode(salvo.GameID, salvoMsg); err == nil {
+		s.Hub.Broadcast(salvo.GameID, data)
+	}
+
+	s.broadcastTurn(salvo.GameID)
+	s.playBot(salvo.GameID)
 }
 
-func (s *Server) SendInitialState(client *Client) {
-	state, err := s.Store.GetState(context.Background(), client.GameID, client.Player)
+func (s *Server) handleResign(client *Client, payload json.RawMessage) {
+	var resign ResignPayload
+	if err := json.Unmarshal(payload, &resign); err != nil {
+		s.sendError(client, "invalid resign payload")
+		return
+	}
+	if resign.GameID != client.GameID {
+		s.sendError(client, "game mismatch")
+		return
+	}
+
+	meta, err := s.Store.Resign(context.Background(), resign.GameID, client.Player)
 	if err != nil {
-		s.sendError(client, "failed to load game state")
+		if s.Logger != nil {
+			s.Logger.Printf("resign failed game_id=%s player=%s err=%v", resign.GameID, client.Player, err)
+		}
+		s.sendStoreError(client, err)
 		return
 	}
-	s.sendState(client, state)
+
+	if s.Logger != nil {
+		s.Logger.Printf("game resigned game_id=%s player=%s reason=%s", meta.ID, client.Player, meta.FinishReason)
+	}
+	s.GameFinished(meta)
 }
 
-func (s *Server) sendState(client *Client, state redisstore.GameState) {
-	payload := GameStatePayload{
-		GameID: state.GameID,
-		Turn:   state.Turn,
-		Status: state.Status,
-		Winner: state.Winner,
-		Shots:  state.Shots,
-		Ships:  state.Ships,
-	}
-	msg := ServerMessage{Type: "game_state", Payload: payload}
-	data, err := json.Marshal(msg)
+func (s *Server) playBot(gameID string) {
+	if s.Bot == nil {
+		return
+	}
+
+	turn, err := s.Bot.TakeTurn(context.Background(), gameID)
 	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("bot turn failed game_id=%s err=%v", gameID, err)
+		}
 		return
 	}
-	client.Send <- data
+	if len(turn.Moves) == 0 {
+		return
+	}
+
+	if s.Logger != nil {
+		s.Logger.Printf("bot fired game_id=%s player=%s shots=%d", gameID, turn.Player, len(turn.Moves))
+	}
+
+	var msg ServerMessage
+	if turn.Salvo {
+		shots := make([]ShotResultPayload, 0, len(turn.Moves))
+		for _, move := range turn.Moves {
+			shots = append(shots, shotPayload(gameID, turn.Player, CoordPayload{Row: move.Coord.Row, Col: move.Coord.Col}, move.Result))
+		}
+		msg = ServerMessage{
+			Type:    "salvo_result",
+			Seq:     turn.Moves[len(turn.Moves)-1].Result.Seq,
+			Payload: SalvoResultPayload{GameID: gameID, Player: turn.Player, Shots: shots},
+		}
+	} else {
+		move := turn.Moves[0]
+		msg = ServerMessage{
+			Type:    "shot_result",
+			Seq:     move.Result.Seq,
+			Payload: shotPayload(gameID, turn.Player, CoordPayload{Row: move.Coord.Row, Col: move.Coord.Col}, move.Result),
+		}
+	}
+	if data, err := s.encode(gameID, msg); err == nil {
+		s.Hub.Broadcast(gameID, data)
+	}
+
+	s.broadcastTurn(gameID)
 }
 
-func (s *Server) broadcastState(gameID string, state redisstore.GameState) {
-	payload := GameStatePayload{
-		GameID: state.GameID,
-		Turn:   state.Turn,
-		Status: state.Status,
-		Winner: state.Winner,
-		Shots:  state.Shots,
-		Ships:  state.Ships,
-	}
-	msg := ServerMessage{Type: "game_state", Payload: payload}
-	data, err := json.Marshal(msg)
+func (s *Server) broadcastTurn(gameID string) {
+	meta, err := s.Store.GetMeta(context.Background(), gameID)
 	if err != nil {
 		return
 	}
-	s.Hub.Broadcast(gameID, data)
+
+	turnMsg := ServerMessage{
+		Type: "turn_changed",
+		Seq:  meta.EventSeq,
+		Payload: TurnChangedPayload{
+			GameID:   gameID,
+			Turn:     meta.Turn,
+			Deadline: unixMilli(meta.TurnDeadline),
+		},
+	}
+	if data, err := s.encode(gameID, turnMsg); err == nil {
+		s.Hub.Broadcast(gameID, data)
+	}
+
+	if meta.Clock.Enabled() {
+		clockMsg := ServerMessage{Type: "clock", Seq: meta.EventSeq, Payload: clockPayload(gameID, meta.Clock)}
+		if data, err := s.encode(gameID, clockMsg); err == nil {
+			s.Hub.Broadcast(gameID, data)
+		}
+	}
+
+	s.updateSpectators(gameID)
+	if meta.Status == "finished" {
+		s.GameFinished(meta)
+	}
 }
 
-func (s *Server) sendError(client *Client, message string) {
-	msg := ServerMessage{Type: "error", Payload: ErrorPayload{Message: message}}
-	data, err := json.Marshal(msg)
+// GameFinished tells both players how the game ended. Archiving is picked up
+// from the outbox by the archive worker.
+func (s *Server) GameFinished(meta redisstore.GameMeta) {
+	finished := ServerMessage{
+		Type: "game_finished",
+		Seq:  meta.EventSeq,
+		Payload: GameFinishedPayload{
+			GameID: meta.ID,
+			Winner: meta.Winner,
+			Reason: meta.FinishReason,
+		},
+	}
+	if data, err := s.encode(meta.ID, finished); err == nil {
+		s.Hub.Broadcast(meta.ID, data)
+	}
+}
+
+func (s *Server) SendInitialState(client *Client) {
+	if isUserRoom(client.GameID) {
+		s.sendPendingMatch(client)
+		return
+	}
+	if client.Role == auth.RoleSpectator {
+		s.sendSpectatorState(client)
+		return
+	}
+	if client.LastSeq > 0 {
+		s.resume(client, client.LastSeq)
+		return
+	}
+	s.sendSnapshot(client)
+}
+
+// sendSnapshot sends a player the full game_state, stamped with the seq it was
+// read at.
+func (s *Server) sendSnapshot(client *Client) {
+	state, err := s.Store.GetState(context.Background(), client.GameID, client.Player)
+	if err != nil {
+		s.sendError(client, "failed to load game state")
+		return
+	}
+	payload := statePayload(state)
+	payload.Chat = s.chatHistory(client.GameID)
+	payload.Presence = s.playerPresence(client, state.Opponent)
+	data, err := json.Marshal(ServerMessage{Type: "game_state", Seq: state.EventSeq, Payload: payload})
 	if err != nil {
 		return
 	}
 	client.Send <- data
 }
 
+func (s *Server) broadcastState(gameID string) {
+	for _, player := range []string{"p1", "p2"} {
+		state, err := s.Store.GetState(context.Background(), gameID, player)
+		if err != nil {
+			continue
+		}
+		msg := ServerMessage{Type: "game_state", Seq: state.EventSeq, Payload: statePayload(state)}
+		data, err := s.encode(gameID, msg)
+		if err != nil {
+			continue
+		}
+		s.Hub.SendToPlayer(gameID, player, data)
+	}
+	s.updateSpectators(gameID)
+}
+
+func statePayload(state redisstore.GameState) GameStatePayload {
+	payload := GameStatePayload{
+		GameID:        state.GameID,
+		Player:        state.Player,
+		Opponent:      state.Opponent,
+		Turn:          state.Turn,
+		TurnDeadline:  unixMilli(state.TurnDeadline),
+		Status:        state.Status,
+		Winner:        state.Winner,
+		Reason:        state.FinishReason,
+		Shots:         state.Shots,
+		IncomingShots: state.IncomingShots,
+		Ships:         state.Ships,
+		Rules:         rulesPayload(state.Rules),
+	}
+	if state.Clock.Enabled() {
+		clock := clockPayload(state.GameID, state.Clock)
+		payload.Clock = &clock
+	}
+	return payload
+}
+
+func clockPayload(gameID string, clock redisstore.Clock) ClockPayload {
+	now := time.Now()
+	return ClockPayload{
+		GameID:      gameID,
+		P1:          clock.Remaining("p1", now).Milliseconds(),
+		P2:          clock.Remaining("p2", now).Milliseconds(),
+		IncrementMs: clock.Increment.Milliseconds(),
+		Running:     clock.Running,
+	}
+}
+
+func rulesPayload(rules game.Ruleset) RulesetPayload {
+	fleet := make([]ShipSpecPayload, 0, len(rules.Fleet))
+	for _, spec := range rules.Fleet {
+		fleet = append(fleet, ShipSpecPayload{
+			Type:  string(spec.Type),
+			Size:  spec.Size,
+			Count: spec.Count,
+		})
+	}
+	return RulesetPayload{
+		Name:         rules.Name,
+		Width:        rules.Width,
+		Height:       rules.Height,
+		Fleet:        fleet,
+		Adjacency:    string(rules.Adjacency),
+		ShotsPerTurn: rules.ShotsPerTurn,
+		Salvo:        string(rules.Salvo),
+	}
+}
+
+func (s *Server) sendError(client *Client, message string) {
+	s.reply(client, ServerMessage{Type: "error", Payload: ErrorPayload{Message: message}})
+}
+
+func shotPayload(gameID string, player string, coord CoordPayload, result redisstore.ShotResult) ShotResultPayload {
+	return ShotResultPayload{
+		GameID:  gameID,
+		Player:  player,
+		Coord:   coord,
+		Outcome: outcomeLabel(result.Outcome),
+		Ship:    string(result.ShipType),
+	}
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
 func outcomeLabel(outcome game.ShotOutcome) string {
 	switch outcome {
 	case game.ShotHit:
@@ -9,12 +9,14 @@ import (
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/bot"
 	"shipsgame/internal/game"
 	redisstore "shipsgame/internal/store/redis"
 )
 
 type messageEnvelope struct {
 	Type    string          `json:"type"`
+	Seq     int64           `json:"seq"`
 	Payload json.RawMessage `json:"payload"`
 }
 
@@ -113,7 +115,7 @@ func TestHandlePlaceShipsSuccess(t *testing.T) {
 	wsServer, store, cleanup := newTestServer(t)
 	defer cleanup()
 
-	meta, err := store.CreateGame(context.Background())
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -142,7 +144,7 @@ func TestHandleFireBroadcasts(t *testing.T) {
 	wsServer, store, cleanup := newTestServer(t)
 	defer cleanup()
 
-	meta, err := store.CreateGame(context.Background())
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
 	if err != nil {
 		t.Fatalf("create game: %v", err)
 	}
@@ -179,3 +181,243 @@ func TestHandleFireBroadcasts(t *testing.T) {
 		t.Fatalf("expected shot_result and turn_changed, got %s and %s", first.Type, second.Type)
 	}
 }
+
+func TestHandlePlaceShipsDoesNotRevealFleet(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 2)}
+	p2 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Send: make(chan []byte, 2)}
+	registerClient(wsServer.Hub, p1)
+	registerClient(wsServer.Hub, p2)
+
+	payload := PlaceShipsPayload{
+		GameID: meta.ID,
+		Ships: []ShipPayload{
+			{Type: "destroyer", Cells: []CoordPayload{{Row: 0, Col: 0}, {Row: 0, Col: 1}}},
+		},
+	}
+	body, _ := json.Marshal(payload)
+	env := ClientMessage{Type: "place_ships", Payload: body}
+	data, _ := json.Marshal(env)
+	wsServer.handleMessage(p1, data)
+
+	own := readStatePayload(t, p1.Send)
+	if own.Player != "p1" || len(own.Ships["destroyer"]) != 2 {
+		t.Fatalf("expected p1 to see own destroyer, got %+v", own)
+	}
+
+	other := readStatePayload(t, p2.Send)
+	if other.Player != "p2" {
+		t.Fatalf("expected p2 view, got %s", other.Player)
+	}
+	if len(other.Ships) != 0 {
+		t.Fatalf("expected p2 to see no ships, got %v", other.Ships)
+	}
+}
+
+func readStatePayload(t *testing.T, ch <-chan []byte) GameStatePayload {
+	msg := readMessage(t, ch)
+	if msg.Type != "game_state" {
+		t.Fatalf("expected game_state, got %s", msg.Type)
+	}
+	var state GameStatePayload
+	if err := json.Unmarshal(msg.Payload, &state); err != nil {
+		t.Fatalf("unmarshal state: %v", err)
+	}
+	return state
+}
+
+func TestHandleFireSalvoBroadcasts(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{Ruleset: game.SalvoRuleset()})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	p1 := redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		game.Cruiser:   {{Row: 4, Col: 0}, {Row: 4, Col: 1}, {Row: 4, Col: 2}},
+	}
+	p2 := redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 2, Col: 0}, {Row: 2, Col: 1}},
+	}
+	if err := store.PlaceShips(context.Background(), meta.ID, "p1", p1); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+	if err := store.PlaceShips(context.Background(), meta.ID, "p2", p2); err != nil {
+		t.Fatalf("place p2: %v", err)
+	}
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	payload := FireSalvoPayload{GameID: meta.ID, Coords: []CoordPayload{{Row: 2, Col: 0}, {Row: 7, Col: 7}}}
+	body, _ := json.Marshal(payload)
+	env := ClientMessage{Type: "fire_salvo", Payload: body}
+	data, _ := json.Marshal(env)
+	wsServer.handleMessage(client, data)
+
+	msg := readMessage(t, client.Send)
+	if msg.Type != "salvo_result" {
+		t.Fatalf("expected salvo_result, got %s", msg.Type)
+	}
+	var result SalvoResultPayload
+	if err := json.Unmarshal(msg.Payload, &result); err != nil {
+		t.Fatalf("unmarshal salvo: %v", err)
+	}
+	if len(result.Shots) != 2 || result.Shots[0].Outcome != "hit" || result.Shots[1].Outcome != "miss" {
+		t.Fatalf("unexpected salvo result: %+v", result)
+	}
+
+	if msg := readMessage(t, client.Send); msg.Type != "turn_changed" {
+		t.Fatalf("expected turn_changed, got %s", msg.Type)
+	}
+}
+
+func TestHandleFireAgainstBot(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+	wsServer.Bot = bot.NewPlayer(store, 1)
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{Opponent: redisstore.OpponentAI, Difficulty: "easy"})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if err := wsServer.Bot.PlaceFleet(context.Background(), meta.ID); err != nil {
+		t.Fatalf("place bot fleet: %v", err)
+	}
+	if err := store.PlaceShips(context.Background(), meta.ID, "p1", redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, client)
+
+	payload := FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 9, Col: 9}}
+	body, _ := json.Marshal(payload)
+	env := ClientMessage{Type: "fire", Payload: body}
+	data, _ := json.Marshal(env)
+	wsServer.handleMessage(client, data)
+
+	expected := []string{"shot_result", "turn_changed", "shot_result", "turn_changed"}
+	for _, want := range expected {
+		if msg := readMessage(t, client.Send); msg.Type != want {
+			t.Fatalf("expected %s, got %s", want, msg.Type)
+		}
+	}
+
+	stored, err := store.GetMeta(context.Background(), meta.ID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if stored.Turn != "p1" {
+		t.Fatalf("expected turn back to p1, got %s", stored.Turn)
+	}
+}
+
+func TestHandleAutoPlacePreviewAndCommit(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	seed := int64(11)
+	keep := []ShipPayload{{Type: "destroyer", Cells: []CoordPayload{{Row: 0, Col: 0}, {Row: 0, Col: 1}}}}
+	preview := func() FleetPreviewPayload {
+		body, _ := json.Marshal(AutoPlacePayload{GameID: meta.ID, Keep: keep, Seed: &seed})
+		data, _ := json.Marshal(ClientMessage{Type: "auto_place", Payload: body})
+		wsServer.handleMessage(client, data)
+
+		msg := readMessage(t, client.Send)
+		if msg.Type != "fleet_preview" {
+			t.Fatalf("expected fleet_preview, got %s", msg.Type)
+		}
+		var fleet FleetPreviewPayload
+		if err := json.Unmarshal(msg.Payload, &fleet); err != nil {
+			t.Fatalf("unmarshal preview: %v", err)
+		}
+		return fleet
+	}
+
+	first := preview()
+	second := preview()
+	if len(first.Ships) != 5 {
+		t.Fatalf("expected full fleet, got %d ships", len(first.Ships))
+	}
+	firstJSON, _ := json.Marshal(first)
+	secondJSON, _ := json.Marshal(second)
+	if string(firstJSON) != string(secondJSON) {
+		t.Fatalf("expected seeded previews to match")
+	}
+
+	state, err := store.GetState(context.Background(), meta.ID, "p1")
+	if err != nil {
+		t.Fatalf("get state: %v", err)
+	}
+	if len(state.Ships) != 0 {
+		t.Fatalf("expected preview not to place ships")
+	}
+
+	body, _ := json.Marshal(AutoPlacePayload{GameID: meta.ID, Keep: keep, Commit: true, Seed: &seed})
+	data, _ := json.Marshal(ClientMessage{Type: "auto_place", Payload: body})
+	wsServer.handleMessage(client, data)
+
+	placed := readStatePayload(t, client.Send)
+	if len(placed.Ships) != 5 {
+		t.Fatalf("expected 5 ships placed, got %d", len(placed.Ships))
+	}
+	if cells := placed.Ships["destroyer"]; len(cells) != 2 || cells[0][0] != 0 || cells[0][1] != 0 {
+		t.Fatalf("expected kept destroyer at 0,0, got %v", cells)
+	}
+}
+
+func TestHandleResignBroadcastsFinish(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{})
+
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 2)}
+	p2 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Send: make(chan []byte, 2)}
+	registerClient(wsServer.Hub, p1)
+	registerClient(wsServer.Hub, p2)
+
+	body, _ := json.Marshal(ResignPayload{GameID: meta.ID})
+	data, _ := json.Marshal(ClientMessage{Type: "resign", Payload: body})
+	wsServer.handleMessage(p1, data)
+
+	for _, client := range []*Client{p1, p2} {
+		msg := readMessage(t, client.Send)
+		if msg.Type != "game_finished" {
+			t.Fatalf("expected game_finished, got %s", msg.Type)
+		}
+		var finished GameFinishedPayload
+		if err := json.Unmarshal(msg.Payload, &finished); err != nil {
+			t.Fatalf("unmarshal finished: %v", err)
+		}
+		if finished.Winner != "p2" || finished.Reason != redisstore.FinishResigned {
+			t.Fatalf("expected p2 to win by resignation, got %+v", finished)
+		}
+	}
+
+	wsServer.handleMessage(p2, data)
+	if msg := readMessage(t, p2.Send); msg.Type != "error" {
+		t.Fatalf("expected error resigning a finished game, got %s", msg.Type)
+	}
+}
@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+// Spectators join a game room read-only. Live they get every broadcast plus
+// spectator_state, which shows shots and sunk ships but no ship still
+// afloat. Games created with a reveal delay also send spectator_reveal, the
+// game as it stood that long ago; it shows both fleets only once the game is
+// over.
+
+func (s *Server) sendSpectatorState(client *Client) {
+	state, err := s.Store.GetSpectatorState(context.Background(), client.GameID)
+	if err != nil {
+		s.sendError(client, "failed to load game state")
+		return
+	}
+	payload := spectatorStatePayload(state)
+	payload.Chat = s.chatHistory(client.GameID)
+	payload.Presence = s.presence(client.GameID, state.Opponent)
+	if data, err := json.Marshal(ServerMessage{Type: "spectator_state", Seq: state.EventSeq, Payload: payload}); err == nil {
+		client.Send <- data
+	}
+	if state.RevealDelay <= 0 {
+		return
+	}
+
+	reveal, err := s.Store.GetRevealState(context.Background(), client.GameID, time.Now().Add(-state.RevealDelay))
+	if err != nil {
+		return
+	}
+	if data, err := s.encode(client.GameID, ServerMessage{Type: "spectator_reveal", Payload: revealPayload(reveal)}); err == nil {
+		client.Send <- data
+	}
+}
+
+// updateSpectators sends the current spectator view to every watcher and,
+// for games with a reveal delay, schedules the view of this moment for when
+// the delay has passed. Games without spectators on any instance are
+// skipped.
+func (s *Server) updateSpectators(gameID string) {
+	if !s.watched(gameID) {
+		return
+	}
+	state, err := s.Store.GetSpectatorState(context.Background(), gameID)
+	if err != nil {
+		return
+	}
+	if data, err := s.encode(gameID, ServerMessage{Type: "spectator_state", Seq: state.EventSeq, Payload: spectatorStatePayload(state)}); err == nil {
+		s.Hub.SendToSpectators(gameID, data)
+	}
+	if state.RevealDelay <= 0 {
+		return
+	}
+
+	asOf := time.Now()
+	time.AfterFunc(state.RevealDelay, func() {
+		s.sendReveal(gameID, asOf)
+	})
+}
+
+func (s *Server) sendReveal(gameID string, asOf time.Time) {
+	if !s.watched(gameID) {
+		return
+	}
+	reveal, err := s.Store.GetRevealState(context.Background(), gameID, asOf)
+	if err != nil {
+		return
+	}
+	if data, err := s.encode(gameID, ServerMessage{Type: "spectator_reveal", Payload: revealPayload(reveal)}); err == nil {
+		s.Hub.SendToSpectators(gameID, data)
+	}
+}
+
+// watched reports whether gameID has spectators. When that can't be told it
+// assumes so, as a missed update is worse than a wasted one.
+func (s *Server) watched(gameID string) bool {
+	watched, err := s.Store.HasSpectators(context.Background(), gameID)
+	return err != nil || watched
+}
+
+func spectatorStatePayload(state redisstore.SpectatorState) SpectatorStatePayload {
+	payload := SpectatorStatePayload{
+		GameID:        state.GameID,
+		Turn:          state.Turn,
+		TurnDeadline:  unixMilli(state.TurnDeadline),
+		Status:        state.Status,
+		Winner:        state.Winner,
+		Reason:        state.FinishReason,
+		Shots:         state.Shots,
+		SunkShips:     state.SunkShips,
+		Rules:         rulesPayload(state.Rules),
+		RevealDelayMs: state.RevealDelay.Milliseconds(),
+	}
+	if state.Clock.Enabled() {
+		clock := clockPayload(state.GameID, state.Clock)
+		payload.Clock = &clock
+	}
+	return payload
+}
+
+func revealPayload(state redisstore.RevealState) SpectatorRevealPayload {
+	return SpectatorRevealPayload{
+		GameID: state.GameID,
+		AsOf:   state.AsOf.UnixMilli(),
+		Status: state.Status,
+		Winner: state.Winner,
+		Reason: state.FinishReason,
+		Shots:  state.Shots,
+		Ships:  state.Ships,
+	}
+}
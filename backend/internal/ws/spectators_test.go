@@ -0,0 +1,189 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func connectSpectator(t *testing.T, wsServer *Server, gameID string) *Client {
+	token, err := auth.SignToken("secret", auth.Claims{
+		GameID:           gameID,
+		Role:             auth.RoleSpectator,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/ws", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	identity, err := wsServer.authenticate(req)
+	if err != nil || identity.Role != auth.RoleSpectator || identity.Player != "" {
+		t.Fatalf("expected spectator identity, got %+v %v", identity, err)
+	}
+
+	client := &Client{Hub: wsServer.Hub, ID: "watcher", GameID: identity.GameID, Role: identity.Role, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, client)
+	wsServer.Joined(client)
+	return client
+}
+
+func readSpectatorState(t *testing.T, ch <-chan []byte) SpectatorStatePayload {
+	msg := readMessage(t, ch)
+	if msg.Type != "spectator_state" {
+		t.Fatalf("expected spectator_state, got %s", msg.Type)
+	}
+	var state SpectatorStatePayload
+	if err := json.Unmarshal(msg.Payload, &state); err != nil {
+		t.Fatalf("unmarshal state: %v", err)
+	}
+	return state
+}
+
+func TestSpectatorIsReadOnlyAndSeesNoFleets(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta, err := store.CreateGame(context.Background(), redisstore.GameOptions{})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, p1)
+	watcher := connectSpectator(t, wsServer, meta.ID)
+
+	wsServer.SendInitialState(watcher)
+	if state := readSpectatorState(t, watcher.Send); state.GameID != meta.ID || state.Status != "waiting" {
+		t.Fatalf("unexpected initial spectator state %+v", state)
+	}
+
+	body, _ := json.Marshal(PlaceShipsPayload{
+		GameID: meta.ID,
+		Ships:  []ShipPayload{{Type: "destroyer", Cells: []CoordPayload{{Row: 0, Col: 0}, {Row: 0, Col: 1}}}},
+	})
+	data, _ := json.Marshal(ClientMessage{Type: "place_ships", Payload: body})
+	wsServer.handleMessage(watcher, data)
+	if msg := readMessage(t, watcher.Send); msg.Type != "error" {
+		t.Fatalf("expected read-only error, got %s", msg.Type)
+	}
+
+	wsServer.handleMessage(p1, data)
+	if own := readStatePayload(t, p1.Send); len(own.Ships["destroyer"]) != 2 {
+		t.Fatalf("expected p1 to see own destroyer, got %+v", own)
+	}
+	state := readSpectatorState(t, watcher.Send)
+	if len(state.SunkShips["p1"]) != 0 {
+		t.Fatalf("expected no ships shown to spectator, got %+v", state.SunkShips)
+	}
+	expectNoMessage(t, watcher.Send)
+}
+
+func TestSpectatorRevealIsDelayed(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta, err := store.CreateGame(ctx, redisstore.GameOptions{RevealDelay: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	for player, ship := range map[string][]game.Coord{
+		"p1": {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+		"p2": {{Row: 5, Col: 5}, {Row: 5, Col: 6}},
+	} {
+		if err := store.PlaceShips(ctx, meta.ID, player, redisstore.ShipsPlacement{game.Destroyer: ship}); err != nil {
+			t.Fatalf("place ships: %v", err)
+		}
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	watcher := connectSpectator(t, wsServer, meta.ID)
+	wsServer.SendInitialState(watcher)
+	if state := readSpectatorState(t, watcher.Send); state.RevealDelayMs != 100 {
+		t.Fatalf("expected reveal delay in state, got %+v", state)
+	}
+	var reveal SpectatorRevealPayload
+	msg := readMessage(t, watcher.Send)
+	if msg.Type != "spectator_reveal" || json.Unmarshal(msg.Payload, &reveal) != nil {
+		t.Fatalf("expected spectator_reveal, got %s", msg.Type)
+	}
+	if len(reveal.Ships["p1"]) != 0 || len(reveal.Ships["p2"]) != 0 {
+		t.Fatalf("expected no afloat ships revealed while the game runs, got %+v", reveal.Ships)
+	}
+
+	p1 := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 8)}
+	body, _ := json.Marshal(FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 5, Col: 5}})
+	data, _ := json.Marshal(ClientMessage{Type: "fire", Payload: body})
+	wsServer.handleMessage(p1, data)
+
+	for _, want := range []string{"shot_result", "turn_changed", "spectator_state"} {
+		if msg := readMessage(t, watcher.Send); msg.Type != want {
+			t.Fatalf("expected %s, got %s", want, msg.Type)
+		}
+	}
+	expectNoMessage(t, watcher.Send)
+
+	msg = readMessage(t, watcher.Send)
+	if msg.Type != "spectator_reveal" || json.Unmarshal(msg.Payload, &reveal) != nil {
+		t.Fatalf("expected delayed spectator_reveal, got %s", msg.Type)
+	}
+	if reveal.Shots["p1"]["5,5"] != "hit" {
+		t.Fatalf("expected the shot in the delayed reveal, got %+v", reveal.Shots)
+	}
+}
+
+func TestHubKeepsPlayerMessagesFromSpectators(t *testing.T) {
+	for name, broadcaster := range broadcasters(t) {
+		t.Run(name, func(t *testing.T) {
+			first, second := newRelayedHubs(t, broadcaster)
+
+			p1 := &Client{Hub: second, GameID: "game", Player: "p1", Role: auth.RolePlayer, Send: make(chan []byte, 4)}
+			watcher := &Client{Hub: second, GameID: "game", Player: "p1", Role: auth.RoleSpectator, Send: make(chan []byte, 4)}
+			registerClient(second, p1)
+			registerClient(second, watcher)
+
+			first.SendToPlayer("game", "p1", []byte(`{"type":"game_state"}`))
+			if msg := readMessage(t, p1.Send); msg.Type != "game_state" {
+				t.Fatalf("expected game_state for p1, got %s", msg.Type)
+			}
+			expectNoMessage(t, watcher.Send)
+
+			first.SendToSpectators("game", []byte(`{"type":"spectator_state"}`))
+			if msg := readMessage(t, watcher.Send); msg.Type != "spectator_state" {
+				t.Fatalf("expected spectator_state, got %s", msg.Type)
+			}
+			expectNoMessage(t, p1.Send)
+		})
+	}
+}
+
+func TestUnwatchedGameSkipsSpectatorUpdates(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{RevealDelay: time.Millisecond})
+	// Registered with the hub but never announced to presence, as on an
+	// instance that lost track of it: nobody counts as watching.
+	watcher := &Client{Hub: wsServer.Hub, GameID: meta.ID, Role: auth.RoleSpectator, Send: make(chan []byte, 8)}
+	registerClient(wsServer.Hub, watcher)
+
+	wsServer.updateSpectators(meta.ID)
+	wsServer.sendReveal(meta.ID, time.Now())
+	expectNoMessage(t, watcher.Send)
+
+	wsServer.Joined(watcher)
+	wsServer.updateSpectators(meta.ID)
+	if state := readSpectatorState(t, watcher.Send); state.GameID != meta.ID {
+		t.Fatalf("unexpected spectator state %+v", state)
+	}
+	if msg := readMessage(t, watcher.Send); msg.Type != "spectator_reveal" {
+		t.Fatalf("expected delayed spectator_reveal, got %s", msg.Type)
+	}
+}
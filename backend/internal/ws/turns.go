@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	redisstore "shipsgame/internal/store/redis"
+)
+
+const sweepBatchSize = 100
+
+// RunTurnSweeper periodically ends turns whose clock ran out, and replays the
+// bot's move in AI games where it did not land, for example after a Redis
+// error. It is safe to run on every backend instance: the store only lets one
+// caller act on a given expired turn.
+func (s *Server) RunTurnSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweepTurns(ctx)
+		}
+	}
+}
+
+func (s *Server) sweepTurns(ctx context.Context) {
+	gameIDs, err := s.Store.ExpiredTurns(ctx, time.Now(), sweepBatchSize)
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Printf("turn sweep failed err=%v", err)
+		}
+		return
+	}
+
+	for _, gameID := range gameIDs {
+		expiry, err := s.Store.ExpireTurn(ctx, gameID)
+		if errors.Is(err, redisstore.ErrBotTurn) {
+			s.playBot(gameID)
+			continue
+		}
+		if err != nil {
+			if !errors.Is(err, redisstore.ErrTurnNotExpired) && !errors.Is(err, redisstore.ErrGameNotFound) && s.Logger != nil {
+				s.Logger.Printf("turn expiry failed game_id=%s err=%v", gameID, err)
+			}
+			continue
+		}
+		s.announceExpiry(expiry)
+	}
+}
+
+func (s *Server) announceExpiry(expiry redisstore.TurnExpiry) {
+	if expiry.Flagged {
+		if s.Logger != nil {
+			s.Logger.Printf("clock expired game_id=%s player=%s", expiry.GameID, expiry.Player)
+		}
+		s.broadcastTurn(expiry.GameID)
+		return
+	}
+
+	action := redisstore.TimeoutRandomShot
+	if expiry.Skipped {
+		action = redisstore.TimeoutSkip
+	}
+	if s.Logger != nil {
+		s.Logger.Printf("turn expired game_id=%s player=%s action=%s", expiry.GameID, expiry.Player, action)
+	}
+
+	timeoutMsg := ServerMessage{
+		Type: "turn_timeout",
+		Seq:  expiry.Seq,
+		Payload: TurnTimeoutPayload{
+			GameID: expiry.GameID,
+			Player: expiry.Player,
+			Action: action,
+		},
+	}
+	if data, err := s.encode(expiry.GameID, timeoutMsg); err == nil {
+		s.Hub.Broadcast(expiry.GameID, data)
+	}
+
+	if !expiry.Skipped {
+		shotMsg := ServerMessage{
+			Type:    "shot_result",
+			Seq:     expiry.Result.Seq,
+			Payload: shotPayload(expiry.GameID, expiry.Player, CoordPayload{Row: expiry.Coord.Row, Col: expiry.Coord.Col}, expiry.Result),
+		}
+		if data, err := s.encode(expiry.GameID, shotMsg); err == nil {
+			s.Hub.Broadcast(expiry.GameID, data)
+		}
+	}
+
+	s.broadcastTurn(expiry.GameID)
+	s.playBot(expiry.GameID)
+}
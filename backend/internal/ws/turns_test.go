@@ -0,0 +1,202 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"shipsgame/internal/bot"
+	"shipsgame/internal/game"
+	redisstore "shipsgame/internal/store/redis"
+)
+
+func startTestGame(t *testing.T, store *redisstore.Client, opts redisstore.GameOptions) redisstore.GameMeta {
+	ctx := context.Background()
+	meta, err := store.CreateGame(ctx, opts)
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if err := store.PlaceShips(ctx, meta.ID, "p1", redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+	if err := store.PlaceShips(ctx, meta.ID, "p2", redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 2, Col: 0}, {Row: 2, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p2: %v", err)
+	}
+	return meta
+}
+
+func TestSweepTurnsSkipsExpiredTurn(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTestGame(t, store, redisstore.GameOptions{TurnTimeout: time.Millisecond})
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	time.Sleep(10 * time.Millisecond)
+	wsServer.sweepTurns(ctx)
+
+	msg := readMessage(t, client.Send)
+	if msg.Type != "turn_timeout" {
+		t.Fatalf("expected turn_timeout, got %s", msg.Type)
+	}
+	var timeout TurnTimeoutPayload
+	if err := json.Unmarshal(msg.Payload, &timeout); err != nil {
+		t.Fatalf("unmarshal timeout: %v", err)
+	}
+	if timeout.Player != "p1" || timeout.Action != redisstore.TimeoutSkip {
+		t.Fatalf("unexpected timeout payload: %+v", timeout)
+	}
+
+	msg = readMessage(t, client.Send)
+	if msg.Type != "turn_changed" {
+		t.Fatalf("expected turn_changed, got %s", msg.Type)
+	}
+	var turn TurnChangedPayload
+	if err := json.Unmarshal(msg.Payload, &turn); err != nil {
+		t.Fatalf("unmarshal turn: %v", err)
+	}
+	if turn.Turn != "p2" || turn.Deadline == 0 {
+		t.Fatalf("expected p2 turn with deadline, got %+v", turn)
+	}
+}
+
+func TestSweepTurnsFinishesGameOnEmptyClock(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	meta := startTestGame(t, store, redisstore.GameOptions{TimeBank: time.Millisecond})
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p2", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	time.Sleep(10 * time.Millisecond)
+	wsServer.sweepTurns(ctx)
+
+	if msg := readMessage(t, client.Send); msg.Type != "turn_changed" {
+		t.Fatalf("expected turn_changed, got %s", msg.Type)
+	}
+
+	msg := readMessage(t, client.Send)
+	if msg.Type != "clock" {
+		t.Fatalf("expected clock, got %s", msg.Type)
+	}
+	var clock ClockPayload
+	if err := json.Unmarshal(msg.Payload, &clock); err != nil {
+		t.Fatalf("unmarshal clock: %v", err)
+	}
+	if clock.P1 != 0 || clock.P2 != 1 || clock.Running != "" {
+		t.Fatalf("unexpected clock payload: %+v", clock)
+	}
+
+	msg = readMessage(t, client.Send)
+	if msg.Type != "game_finished" {
+		t.Fatalf("expected game_finished, got %s", msg.Type)
+	}
+	var finished GameFinishedPayload
+	if err := json.Unmarshal(msg.Payload, &finished); err != nil {
+		t.Fatalf("unmarshal finished: %v", err)
+	}
+	if finished.Winner != "p2" || finished.Reason != redisstore.FinishTimeout {
+		t.Fatalf("expected p2 to win on time, got %+v", finished)
+	}
+}
+
+func TestFireWithEmptyClockFinishesGame(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	meta := startTestGame(t, store, redisstore.GameOptions{TimeBank: time.Millisecond})
+
+	client := &Client{Hub: wsServer.Hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	time.Sleep(10 * time.Millisecond)
+	payload, _ := json.Marshal(FirePayload{GameID: meta.ID, Coord: CoordPayload{Row: 2, Col: 0}})
+	wsServer.handleFire(client, payload)
+
+	if msg := readMessage(t, client.Send); msg.Type != "error" {
+		t.Fatalf("expected error, got %s", msg.Type)
+	}
+	readMessage(t, client.Send)
+	readMessage(t, client.Send)
+	msg := readMessage(t, client.Send)
+	if msg.Type != "game_finished" {
+		t.Fatalf("expected game_finished, got %s", msg.Type)
+	}
+	var finished GameFinishedPayload
+	if err := json.Unmarshal(msg.Payload, &finished); err != nil {
+		t.Fatalf("unmarshal finished: %v", err)
+	}
+	if finished.Winner != "p2" || finished.Reason != redisstore.FinishTimeout {
+		t.Fatalf("expected p2 to win on time, got %+v", finished)
+	}
+}
+
+func TestSweepTurnsReplaysStalledBotMove(t *testing.T) {
+	server := miniredis.RunT(t)
+	store := redisstore.NewClient(redisstore.Config{Addr: server.Addr()})
+	defer store.Close()
+	hub := NewHub()
+	go hub.Run()
+	wsServer := &Server{Hub: hub, Store: store, Bot: bot.NewPlayer(store, 1), Logger: log.New(io.Discard, "", 0)}
+
+	ctx := context.Background()
+	server.SetTime(time.Now().Add(-time.Minute))
+	meta, err := store.CreateGame(ctx, redisstore.GameOptions{Opponent: redisstore.OpponentAI, Difficulty: "easy"})
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+	if err := wsServer.Bot.PlaceFleet(ctx, meta.ID); err != nil {
+		t.Fatalf("place bot fleet: %v", err)
+	}
+	if err := store.PlaceShips(ctx, meta.ID, "p1", redisstore.ShipsPlacement{
+		game.Destroyer: {{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+	}); err != nil {
+		t.Fatalf("place p1: %v", err)
+	}
+	// The player's shot lands but the bot's reply never does.
+	if _, err := store.Fire(ctx, meta.ID, "p1", game.Coord{Row: 9, Col: 9}); err != nil {
+		t.Fatalf("fire: %v", err)
+	}
+	server.SetTime(time.Now())
+
+	client := &Client{Hub: hub, GameID: meta.ID, Player: "p1", Send: make(chan []byte, 4)}
+	registerClient(hub, client)
+
+	wsServer.sweepTurns(ctx)
+
+	msg := readMessage(t, client.Send)
+	if msg.Type != "shot_result" {
+		t.Fatalf("expected shot_result, got %s", msg.Type)
+	}
+	var shot ShotResultPayload
+	if err := json.Unmarshal(msg.Payload, &shot); err != nil {
+		t.Fatalf("unmarshal shot: %v", err)
+	}
+	if shot.Player != bot.Seat {
+		t.Fatalf("expected the bot to fire, got %+v", shot)
+	}
+	if msg := readMessage(t, client.Send); msg.Type != "turn_changed" {
+		t.Fatalf("expected turn_changed, got %s", msg.Type)
+	}
+
+	stored, err := store.GetMeta(ctx, meta.ID)
+	if err != nil {
+		t.Fatalf("get meta: %v", err)
+	}
+	if stored.Turn != "p1" {
+		t.Fatalf("expected turn back to p1, got %s", stored.Turn)
+	}
+}
@@ -0,0 +1,42 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Users connect with their user token to hear about things that happen
+// outside a game, such as matchmaking. Each user gets a room of their own,
+// which the hub relays across instances like any game room.
+const userRoomPrefix = "user:"
+
+func userRoom(userID string) string {
+	return userRoomPrefix + userID
+}
+
+func isUserRoom(room string) bool {
+	return strings.HasPrefix(room, userRoomPrefix)
+}
+
+// NotifyUser sends a message to every connection userID has open with their
+// user token, on any instance.
+func (s *Server) NotifyUser(userID string, msgType string, payload any) {
+	data, err := json.Marshal(ServerMessage{Type: msgType, Payload: payload})
+	if err != nil {
+		return
+	}
+	s.Hub.Broadcast(userRoom(userID), data)
+}
+
+func (s *Server) sendPendingMatch(client *Client) {
+	data, err := s.Store.PendingMatch(context.Background(), strings.TrimPrefix(client.GameID, userRoomPrefix))
+	if err != nil || data == nil {
+		return
+	}
+	msg, err := json.Marshal(ServerMessage{Type: "match_found", Payload: json.RawMessage(data)})
+	if err != nil {
+		return
+	}
+	client.Send <- msg
+}
@@ -0,0 +1,57 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"shipsgame/internal/auth"
+)
+
+func TestUserTokenOpensReadOnlyUserRoom(t *testing.T) {
+	wsServer, store, cleanup := newTestServer(t)
+	defer cleanup()
+
+	token, err := auth.SignUserToken("secret", auth.UserClaims{
+		UserID:           "ada",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	})
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/ws", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	identity, err := wsServer.authenticate(req)
+	if err != nil || identity.GameID != userRoom("ada") || identity.Player != "" || identity.Role != "" {
+		t.Fatalf("expected user room, got %+v %v", identity, err)
+	}
+	room := identity.GameID
+
+	if err := store.SaveMatch(context.Background(), "ada", []byte(`{"game_id":"g1","player":"p2"}`), time.Minute); err != nil {
+		t.Fatalf("save match: %v", err)
+	}
+	client := &Client{Hub: wsServer.Hub, GameID: room, Send: make(chan []byte, 4)}
+	registerClient(wsServer.Hub, client)
+
+	wsServer.SendInitialState(client)
+	msg := readMessage(t, client.Send)
+	var match struct {
+		GameID string `json:"game_id"`
+	}
+	if msg.Type != "match_found" || json.Unmarshal(msg.Payload, &match) != nil || match.GameID != "g1" {
+		t.Fatalf("expected pending match on connect, got %s %s", msg.Type, msg.Payload)
+	}
+
+	wsServer.NotifyUser("ada", "matchmaking_expired", struct{}{})
+	if msg := readMessage(t, client.Send); msg.Type != "matchmaking_expired" {
+		t.Fatalf("expected notification, got %s", msg.Type)
+	}
+
+	wsServer.handleMessage(client, []byte(`{"type":"fire","payload":{}}`))
+	if msg := readMessage(t, client.Send); msg.Type != "error" {
+		t.Fatalf("expected read-only error, got %s", msg.Type)
+	}
+}
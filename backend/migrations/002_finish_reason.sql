@@ -0,0 +1,3 @@
+ALTER TABLE games ADD COLUMN IF NOT EXISTS finish_reason text;
+
+CREATE INDEX IF NOT EXISTS games_finish_reason_idx ON games (finish_reason);
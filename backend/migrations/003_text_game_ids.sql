@@ -0,0 +1,9 @@
+-- Game IDs come from Redis and are hex strings, not UUIDs.
+ALTER TABLE game_events DROP CONSTRAINT IF EXISTS game_events_game_id_fkey;
+
+ALTER TABLE games ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE games ALTER COLUMN id TYPE text USING id::text;
+ALTER TABLE game_events ALTER COLUMN game_id TYPE text USING game_id::text;
+
+ALTER TABLE game_events
+  ADD CONSTRAINT game_events_game_id_fkey FOREIGN KEY (game_id) REFERENCES games(id) ON DELETE CASCADE;
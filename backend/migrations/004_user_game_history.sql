@@ -0,0 +1,3 @@
+-- Per-user history is paged newest first by (created_at, id).
+CREATE INDEX IF NOT EXISTS games_player1_created_idx ON games (player1_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS games_player2_created_idx ON games (player2_id, created_at DESC, id DESC);
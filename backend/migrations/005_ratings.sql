@@ -0,0 +1,15 @@
+ALTER TABLE leaderboard ADD COLUMN IF NOT EXISTS rating double precision NOT NULL DEFAULT 1500;
+
+CREATE INDEX IF NOT EXISTS leaderboard_rating_idx ON leaderboard (rating DESC, total_games DESC);
+
+CREATE TABLE IF NOT EXISTS rating_history (
+  id bigserial PRIMARY KEY,
+  user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  game_id text NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+  rating_before double precision NOT NULL,
+  rating_after double precision NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (game_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS rating_history_user_idx ON rating_history (user_id, created_at DESC);
@@ -32,10 +32,38 @@ export default function Home() {
 
   const apiUrl = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
 
+  const guestLogin = async () => {
+    const res = await fetch(`${apiUrl}/auth/guest`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ display_name: callSign }),
+    });
+    if (!res.ok) {
+      throw new Error("Failed to log in");
+    }
+    const data = await res.json();
+    localStorage.setItem("user_token", data.token);
+    return data.token as string;
+  };
+
+  const userToken = async () => localStorage.getItem("user_token") || guestLogin();
+
+  const handleLogin = async () => {
+    setError(null);
+    try {
+      await guestLogin();
+    } catch (err) {
+      setError("Could not log in");
+    }
+  };
+
   const handleCreate = async () => {
     setError(null);
     try {
-      const res = await fetch(`${apiUrl}/games`, { method: "POST" });
+      const res = await fetch(`${apiUrl}/games`, {
+        method: "POST",
+        headers: { Authorization: `Bearer ${await userToken()}` },
+      });
       if (!res.ok) {
         throw new Error("Failed to create game");
       }
@@ -52,7 +80,10 @@ export default function Home() {
     try {
       const res = await fetch(`${apiUrl}/games/join`, {
         method: "POST",
-        headers: { "Content-Type": "application/json" },
+        headers: {
+          "Content-Type": "application/json",
+          Authorization: `Bearer ${await userToken()}`,
+        },
         body: JSON.stringify({ join_code: joinCode }),
       });
       if (!res.ok) {
@@ -114,7 +145,9 @@ export default function Home() {
                   value={callSign}
                   onChange={(event) => setCallSign(event.target.value)}
                 />
-                <button className={styles.ghostButton}>Enter</button>
+                <button className={styles.ghostButton} onClick={handleLogin}>
+                  Enter
+                </button>
               </div>
               <div className={styles.joinRow}>
                 <input